@@ -0,0 +1,431 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Condition types of a System.
+const (
+	// TypeLabelsSynced indicates whether the labels of a System have been
+	// written to Styra.
+	TypeLabelsSynced xpv1.ConditionType = "LabelsSynced"
+
+	// TypeSystemTypeSupported indicates whether the type of a System is
+	// known to the provider and its parameters apply to the type.
+	TypeSystemTypeSupported xpv1.ConditionType = "SystemTypeSupported"
+
+	// TypeAdopted indicates whether an existing system has been adopted by
+	// a System.
+	TypeAdopted xpv1.ConditionType = "Adopted"
+
+	// TypeAgentsHealthy indicates whether agents of a System are connected
+	// to Styra.
+	TypeAgentsHealthy xpv1.ConditionType = "AgentsHealthy"
+
+	// TypeBundleCompiled indicates whether the latest policy bundle of a
+	// System has been compiled successfully.
+	TypeBundleCompiled xpv1.ConditionType = "BundleCompiled"
+
+	// TypeBundleDeployed indicates whether the policy bundle selected by the
+	// bundle deployment of a System is deployed.
+	TypeBundleDeployed xpv1.ConditionType = "BundleDeployed"
+
+	// TypeSourceControlVerified indicates whether Styra has verified the
+	// source control configuration of a System.
+	TypeSourceControlVerified xpv1.ConditionType = "SourceControlVerified"
+
+	// TypePoliciesValid indicates whether the policy tests and compliance
+	// checks of a System pass.
+	TypePoliciesValid xpv1.ConditionType = "PoliciesValid"
+
+	// TypeDatasourcesHealthy indicates whether the datasources of a System
+	// are executed successfully.
+	TypeDatasourcesHealthy xpv1.ConditionType = "DatasourcesHealthy"
+)
+
+// Condition reasons of a System.
+const (
+	ReasonLabelsSynced   xpv1.ConditionReason = "Synced"
+	ReasonLabelsRejected xpv1.ConditionReason = "Rejected"
+
+	ReasonSystemTypeKnown       xpv1.ConditionReason = "KnownType"
+	ReasonSystemTypeUnknown     xpv1.ConditionReason = "UnknownType"
+	ReasonUnsupportedParameters xpv1.ConditionReason = "UnsupportedParameters"
+
+	ReasonAdopted        xpv1.ConditionReason = "Adopted"
+	ReasonAmbiguousMatch xpv1.ConditionReason = "AmbiguousMatch"
+
+	ReasonAgentsConnected xpv1.ConditionReason = "AgentsConnected"
+	ReasonNoAgents        xpv1.ConditionReason = "NoAgents"
+	ReasonAgentsStale     xpv1.ConditionReason = "AgentsStale"
+	ReasonAgentsUnknown   xpv1.ConditionReason = "AgentsUnknown"
+
+	ReasonBundleCompiled xpv1.ConditionReason = "Compiled"
+	ReasonCompileFailed  xpv1.ConditionReason = "CompileFailed"
+	ReasonNoBundle       xpv1.ConditionReason = "NoBundle"
+	ReasonBundleUnknown  xpv1.ConditionReason = "BundleUnknown"
+
+	ReasonBundleDeployed      xpv1.ConditionReason = "Deployed"
+	ReasonBundleDeployPending xpv1.ConditionReason = "Pending"
+	ReasonInvalidDeployment   xpv1.ConditionReason = "InvalidDeployment"
+
+	ReasonSourceControlVerified xpv1.ConditionReason = "Verified"
+	ReasonVerifyFailed          xpv1.ConditionReason = "VerifyFailed"
+
+	ReasonPoliciesValid        xpv1.ConditionReason = "Valid"
+	ReasonTestsFailed          xpv1.ConditionReason = "TestsFailed"
+	ReasonComplianceViolations xpv1.ConditionReason = "ComplianceViolations"
+	ReasonValidationUnknown    xpv1.ConditionReason = "ValidationUnknown"
+
+	ReasonDatasourcesHealthy xpv1.ConditionReason = "Healthy"
+	ReasonDatasourcesFailing xpv1.ConditionReason = "DatasourcesFailing"
+)
+
+// LabelsSynced returns a condition that indicates the labels of a System
+// have been written to Styra.
+func LabelsSynced() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeLabelsSynced,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonLabelsSynced,
+	}
+}
+
+// LabelsRejected returns a condition that indicates Styra has rejected the
+// labels of a System.
+func LabelsRejected(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeLabelsSynced,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonLabelsRejected,
+		Message:            err.Error(),
+	}
+}
+
+// SystemTypeKnown returns a condition that indicates the type of a System is
+// known to the provider.
+func SystemTypeKnown() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSystemTypeSupported,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSystemTypeKnown,
+	}
+}
+
+// SystemTypeUnknown returns a condition that indicates the type of a System
+// is not known to the provider. The System is still managed but its
+// capabilities are guessed.
+func SystemTypeUnknown(systemType string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSystemTypeSupported,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSystemTypeUnknown,
+		Message:            fmt.Sprintf("system type %q is not known to the provider", systemType),
+	}
+}
+
+// UnsupportedParameters returns a condition that indicates parameters of a
+// System are set that do not apply to its type and are therefore ignored.
+func UnsupportedParameters(systemType string, params ...string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSystemTypeSupported,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnsupportedParameters,
+		Message:            fmt.Sprintf("%s do not apply to system type %q and are ignored", strings.Join(params, ", "), systemType),
+	}
+}
+
+// Adopted returns a condition that indicates an existing system has been
+// adopted by a System.
+func Adopted(id string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAdopted,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAdopted,
+		Message:            fmt.Sprintf("adopted existing system %s", id),
+	}
+}
+
+// AdoptionAmbiguous returns a condition that indicates several existing
+// systems match a System and none of them has been adopted.
+func AdoptionAmbiguous(ids []string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAdopted,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAmbiguousMatch,
+		Message:            fmt.Sprintf("refusing to adopt one of several matching systems: %s", strings.Join(ids, ", ")),
+	}
+}
+
+// AgentsConnected returns a condition that indicates agents of a System are
+// connected to Styra.
+func AgentsConnected(connected, stale int) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAgentsHealthy,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAgentsConnected,
+		Message:            fmt.Sprintf("%d agents connected, %d agents stale", connected, stale),
+	}
+}
+
+// NoAgents returns a condition that indicates no agent has registered with a
+// System.
+func NoAgents() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAgentsHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoAgents,
+		Message:            "no agent has registered with the system",
+	}
+}
+
+// AgentsStale returns a condition that indicates none of the agents of a
+// System has reported to Styra recently.
+func AgentsStale(stale int) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAgentsHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAgentsStale,
+		Message:            fmt.Sprintf("%d agents stale, none connected", stale),
+	}
+}
+
+// AgentsUnknown returns a condition that indicates the agents of a System
+// could not be observed.
+func AgentsUnknown(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeAgentsHealthy,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAgentsUnknown,
+		Message:            err.Error(),
+	}
+}
+
+// BundleCompiled returns a condition that indicates the latest policy bundle
+// of a System has been compiled successfully.
+func BundleCompiled(id string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleCompiled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBundleCompiled,
+		Message:            fmt.Sprintf("compiled bundle %s", id),
+	}
+}
+
+// BundleCompileFailed returns a condition that indicates the latest
+// compilation of the policy bundle of a System has failed.
+func BundleCompileFailed(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleCompiled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCompileFailed,
+		Message:            msg,
+	}
+}
+
+// NoBundle returns a condition that indicates no policy bundle has been
+// compiled for a System yet.
+func NoBundle() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleCompiled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoBundle,
+		Message:            "no bundle has been compiled for the system",
+	}
+}
+
+// BundleUnknown returns a condition that indicates the policy bundle of a
+// System could not be observed.
+func BundleUnknown(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleCompiled,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBundleUnknown,
+		Message:            err.Error(),
+	}
+}
+
+// BundleDeployed returns a condition that indicates the policy bundle
+// selected by the bundle deployment of a System is deployed.
+func BundleDeployed(id string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleDeployed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBundleDeployed,
+		Message:            fmt.Sprintf("deployed bundle %s", id),
+	}
+}
+
+// BundleDeployPending returns a condition that indicates the policy bundle
+// selected by the bundle deployment of a System has not been deployed yet.
+func BundleDeployPending(id string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleDeployed,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBundleDeployPending,
+		Message:            fmt.Sprintf("bundle %s is not deployed yet", id),
+	}
+}
+
+// NoBundleToDeploy returns a condition that indicates no policy bundle can
+// be deployed because none has been compiled for a System yet.
+func NoBundleToDeploy() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleDeployed,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoBundle,
+		Message:            "no bundle has been compiled for the system",
+	}
+}
+
+// InvalidBundleDeployment returns a condition that indicates the bundle
+// deployment of a System cannot be deployed until it is changed, e.g. because
+// the pinned bundle does not exist.
+func InvalidBundleDeployment(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBundleDeployed,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonInvalidDeployment,
+		Message:            err.Error(),
+	}
+}
+
+// SourceControlVerified returns a condition that indicates Styra has verified
+// the source control configuration of a System.
+func SourceControlVerified(sha string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSourceControlVerified,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSourceControlVerified,
+		Message:            fmt.Sprintf("verified revision %s", sha),
+	}
+}
+
+// SourceControlVerifyFailed returns a condition that indicates Styra has
+// rejected the source control configuration of a System.
+func SourceControlVerifyFailed(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSourceControlVerified,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonVerifyFailed,
+		Message:            err.Error(),
+	}
+}
+
+// PoliciesValid returns a condition that indicates all policy tests and
+// compliance checks of a System pass.
+func PoliciesValid(passed int32) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePoliciesValid,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonPoliciesValid,
+		Message:            fmt.Sprintf("%d tests passed", passed),
+	}
+}
+
+// PolicyTestsFailed returns a condition that indicates policy tests of a
+// System fail.
+func PolicyTestsFailed(failed int32, names []string) xpv1.Condition {
+	msg := fmt.Sprintf("%d tests failed", failed)
+	if len(names) > 0 {
+		msg += ": " + strings.Join(names, ", ")
+	}
+	return xpv1.Condition{
+		Type:               TypePoliciesValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTestsFailed,
+		Message:            msg,
+	}
+}
+
+// ComplianceViolations returns a condition that indicates the compliance
+// checks of a System report violations.
+func ComplianceViolations(violations int32) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePoliciesValid,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonComplianceViolations,
+		Message:            fmt.Sprintf("%d compliance violations", violations),
+	}
+}
+
+// ValidationUnknown returns a condition that indicates the policies of a
+// System could not be validated.
+func ValidationUnknown(err error) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypePoliciesValid,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonValidationUnknown,
+		Message:            err.Error(),
+	}
+}
+
+// DatasourcesHealthy returns a condition that indicates no required
+// datasource of a System is failing.
+func DatasourcesHealthy() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDatasourcesHealthy,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDatasourcesHealthy,
+	}
+}
+
+// DatasourcesFailing returns a condition that indicates the given required
+// datasources of a System are failing.
+func DatasourcesFailing(ids []string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDatasourcesHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDatasourcesFailing,
+		Message:            fmt.Sprintf("failing datasources: %s", strings.Join(ids, ", ")),
+	}
+}
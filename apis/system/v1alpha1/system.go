@@ -17,19 +17,22 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"strings"
 	"time"
 
+	"github.com/iancoleman/strcase"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	providerv1alpha1 "github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
 )
 
 // System special annotations.
 const (
-	AnnotationLastPublishedConnectionDetailsHash         = "system.styra.crossplane.io/last-published-connection-details-hash"
-	AnnotationLastPublishedConnectionDetailsCertNotAfter = "system.styra.crossplane.io/last-published-connection-details-cert-not-after"
+	AnnotationLastPublishedConnectionDetailsHash          = "system.styra.crossplane.io/last-published-connection-details-hash"
+	AnnotationLastPublishedConnectionDetailsCertNotAfter  = "system.styra.crossplane.io/last-published-connection-details-cert-not-after"
+	AnnotationLastPublishedConnectionDetailsCertNotBefore = "system.styra.crossplane.io/last-published-connection-details-cert-not-before"
 )
 
 // CustomSystemParameters that are not part of the Styra API spec.
@@ -37,6 +40,134 @@ type CustomSystemParameters struct {
 	// Labels for this systems
 	// +optional
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// Assets of the system that are published as connection details.
+	// Defaults to helm-values for kubernetes systems and opa-config for
+	// custom systems if empty.
+	// +optional
+	Assets []SystemAsset `json:"assets,omitempty"`
+
+	// ConnectionDetailsRenewBefore is the duration before the OPA
+	// certificate in the connection details expires at which the connection
+	// details are published again with a new certificate. It is limited to
+	// half the lifetime of the certificate. They are only published again
+	// after the certificate has expired if not set.
+	// +optional
+	ConnectionDetailsRenewBefore *metav1.Duration `json:"connectionDetailsRenewBefore,omitempty"`
+
+	// AdoptExisting enables the adoption of an existing system if the
+	// external name of the System is not set, e.g. because it was lost in a
+	// restore from backup. A system is adopted if it is the only system of
+	// the same type whose name equals the name of the System or whose
+	// external ID equals externalId. No system is adopted and an error is
+	// reported if several systems match.
+	// +optional
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// BundleDeployment configures which policy bundle is deployed to the
+	// agents of the system. The deployment is not managed if not set.
+	// +optional
+	BundleDeployment *SystemBundleDeployment `json:"bundleDeployment,omitempty"`
+
+	// BundleInterval is the minimum interval between two observations of
+	// the policy bundle of the system. Defaults to 1m. The bundle is
+	// observed on every poll if bundleDeployment is set.
+	// +optional
+	BundleInterval *metav1.Duration `json:"bundleInterval,omitempty"`
+
+	// AgentsInterval is the minimum interval between two observations of
+	// the agents of the system. Defaults to 1m. The agents of system types
+	// without agents, e.g. terraform, are not observed.
+	// +optional
+	AgentsInterval *metav1.Duration `json:"agentsInterval,omitempty"`
+
+	// Validation configures running the policy tests and compliance checks
+	// of the system. The policies are not validated if not set.
+	// +optional
+	Validation *SystemValidation `json:"validation,omitempty"`
+
+	// Policies of the system by their path relative to systems/<system id>,
+	// e.g. policy/com.styra.kubernetes.validating/rules/rules. Policies that
+	// are removed from the map are not deleted.
+	// +optional
+	Policies map[string]SystemPolicy `json:"policies,omitempty"`
+}
+
+// A SystemPolicy is a policy of a System.
+type SystemPolicy struct {
+	// Modules of the policy by their file name, e.g. rules.rego.
+	Modules map[string]SystemPolicyModule `json:"modules"`
+}
+
+// A SystemPolicyModule is a Rego module of a policy. Either rego or
+// configMapKeyRef must be set.
+type SystemPolicyModule struct {
+	// Rego of the module.
+	// +optional
+	Rego *string `json:"rego,omitempty"`
+
+	// ConfigMapKeyRef references the config map key that contains the Rego
+	// of the module.
+	// +optional
+	ConfigMapKeyRef *providerv1alpha1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// A SystemValidation configures running the policy tests and compliance
+// checks of a System.
+type SystemValidation struct {
+	// Enabled runs the policy tests and compliance checks of the system.
+	Enabled bool `json:"enabled"`
+
+	// Interval between two validations of the same bundle. The policies are
+	// validated whenever a new bundle is compiled regardless of the
+	// interval. Defaults to 10m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// Bundle deployment policies.
+const (
+	BundleDeploymentPolicyLatest = "Latest"
+	BundleDeploymentPolicyPinned = "Pinned"
+)
+
+// A SystemBundleDeployment configures which policy bundle is deployed to the
+// agents of a System.
+type SystemBundleDeployment struct {
+	// Policy of the deployment. Latest deploys the most recently compiled
+	// bundle. Pinned deploys the bundle with the given bundleId.
+	// +kubebuilder:validation:Enum=Latest;Pinned
+	Policy string `json:"policy"`
+
+	// BundleID of the bundle that is deployed if the policy is Pinned. The
+	// BundleDeployed condition turns False and nothing is deployed if no
+	// bundle with this ID exists.
+	// +optional
+	BundleID *string `json:"bundleId,omitempty"`
+
+	// Force the deployment even if the bundle is not compatible with the
+	// running agents.
+	// +optional
+	Force *bool `json:"force,omitempty"`
+}
+
+// A SystemAsset is published as connection detail of a System.
+type SystemAsset struct {
+	// Type of the asset. Besides helm-values and opa-config, all asset types
+	// of the Styra API and the special types opa-discovery-config and
+	// install-instructions are supported.
+	// +kubebuilder:validation:MinLength=1
+	Type string `json:"type"`
+
+	// Key of the connection detail the asset is published under. Defaults to
+	// the type in lower camel case, e.g. helmValues.
+	// +optional
+	Key *string `json:"key,omitempty"`
+
+	// Encoding of the published asset.
+	// +kubebuilder:validation:Enum=raw;base64
+	// +optional
+	Encoding *string `json:"encoding,omitempty"`
 }
 
 // A SystemParameters defines desired state of a System
@@ -59,6 +190,10 @@ type SystemParameters struct {
 	// +optional
 	ReadOnly *bool `json:"readOnly,omitempty"`
 
+	// source control configuration of the policies of the system
+	// +optional
+	SourceControl *V1SourceControlConfig `json:"sourceControl,omitempty"`
+
 	// system type e.g. kubernetes
 	// +kubebuilder:validation:Required
 	// +immutable
@@ -75,6 +210,137 @@ type SystemSpec struct {
 type SystemObservation struct {
 	// datasources created for the system
 	Datasources []*V1DatasourceConfig `json:"datasources,omitempty"`
+
+	// agents that have registered with the system
+	// +optional
+	Agents *SystemAgentsObservation `json:"agents,omitempty"`
+
+	// latest policy bundle of the system
+	// +optional
+	Bundle *SystemBundleObservation `json:"bundle,omitempty"`
+
+	// result of the latest validation of the policies of the system
+	// +optional
+	Validation *SystemValidationObservation `json:"validation,omitempty"`
+
+	// hash of the labels that Styra has rejected. The labels are not written
+	// again until they change.
+	// +optional
+	RejectedLabelsHash string `json:"rejectedLabelsHash,omitempty"`
+
+	// hash of the source control config that Styra has verified last. The
+	// config is verified again when it changes.
+	// +optional
+	SourceControlHash string `json:"sourceControlHash,omitempty"`
+}
+
+// A SystemValidationObservation describes the result of the latest policy
+// tests and compliance checks of a System.
+type SystemValidationObservation struct {
+	// BundleID of the latest bundle at the time of the validation.
+	// +optional
+	BundleID string `json:"bundleId,omitempty"`
+
+	// LastValidatedAt is the time of the validation.
+	// +optional
+	LastValidatedAt *metav1.Time `json:"lastValidatedAt,omitempty"`
+
+	// Passed is the number of policy tests that passed.
+	Passed int32 `json:"passed"`
+
+	// Failed is the number of policy tests that failed or could not be
+	// evaluated.
+	Failed int32 `json:"failed"`
+
+	// FailedTests are the names of the failed policy tests.
+	// +optional
+	FailedTests []string `json:"failedTests,omitempty"`
+
+	// Violations is the number of compliance violations. It is not set if
+	// the compliance checks have not completed yet.
+	// +optional
+	Violations *int32 `json:"violations,omitempty"`
+}
+
+// Deploy states of a bundle.
+const (
+	BundleDeployStateActive     = "Active"
+	BundleDeployStateActivating = "Activating"
+	BundleDeployStateInactive   = "Inactive"
+)
+
+// A SystemBundleObservation describes the latest policy bundle compiled for a
+// System.
+type SystemBundleObservation struct {
+	// ID of the bundle.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// Version of the bundle.
+	// +optional
+	Version int64 `json:"version,omitempty"`
+
+	// Revision of the bundle, i.e. its SHA.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// CompiledAt is the time the bundle was compiled.
+	// +optional
+	CompiledAt *metav1.Time `json:"compiledAt,omitempty"`
+
+	// LastDeployedAt is the time the bundle was last deployed.
+	// +optional
+	LastDeployedAt *metav1.Time `json:"lastDeployedAt,omitempty"`
+
+	// DeployState of the bundle. Active if it is served to all agents,
+	// Activating if it is served to some agents and Inactive otherwise.
+	// +optional
+	DeployState string `json:"deployState,omitempty"`
+
+	// CompileErrors of the latest compilation per bundle ID.
+	// +optional
+	CompileErrors map[string]string `json:"compileErrors,omitempty"`
+
+	// LastObservedAt is the time the bundle was last observed.
+	// +optional
+	LastObservedAt *metav1.Time `json:"lastObservedAt,omitempty"`
+}
+
+// A SystemAgentsObservation summarizes the agents of a System.
+type SystemAgentsObservation struct {
+	// Connected is the number of agents that have recently reported to
+	// Styra.
+	Connected int `json:"connected"`
+
+	// Stale is the number of agents that have not reported to Styra
+	// recently.
+	Stale int `json:"stale"`
+
+	// Items are the most recently seen agents of the system. At most 10
+	// agents are listed.
+	// +optional
+	Items []SystemAgent `json:"items,omitempty"`
+
+	// LastObservedAt is the time the agents were last observed.
+	// +optional
+	LastObservedAt *metav1.Time `json:"lastObservedAt,omitempty"`
+}
+
+// A SystemAgent is an agent, e.g. an OPA, that has registered with a System.
+type SystemAgent struct {
+	// ID of the agent.
+	ID string `json:"id"`
+
+	// Version of the agent.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// LastSeen is the time the agent has last reported to Styra.
+	// +optional
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+
+	// Stale is true if the agent has not reported to Styra recently.
+	Stale bool `json:"stale"`
 }
 
 // A SystemStatus represents the status of a System.
@@ -108,28 +374,70 @@ type SystemList struct {
 	Items           []System `json:"items"`
 }
 
-// HasLabels whether the system has labels
-func (in *SystemParameters) HasLabels() bool {
-	// so far only handle labels for kubernetes*
-	return in.Type == "kubernetes:v2"
-}
-
 // System asset types.
 const (
-	SystemAssetTypeHelmValues = "helm-values"
-	SystemAssetTypeOpaConfig  = "opa-config"
+	SystemAssetTypeHelmValues          = "helm-values"
+	SystemAssetTypeOpaConfig           = "opa-config"
+	SystemAssetTypeOpaDiscoveryConfig  = "opa-discovery-config"
+	SystemAssetTypeInstallInstructions = "install-instructions"
+)
+
+// System asset encodings.
+const (
+	SystemAssetEncodingRaw    = "raw"
+	SystemAssetEncodingBase64 = "base64"
 )
 
+// GetAssets gets the assets to publish. The default assets of the system type
+// are returned if none are specified.
+func (in *SystemParameters) GetAssets() []SystemAsset {
+	if len(in.Assets) > 0 {
+		return in.Assets
+	}
+
+	types := in.GetTypeInfo().AssetTypes
+	assets := make([]SystemAsset, len(types))
+	for i, t := range types {
+		assets[i] = SystemAsset{Type: t}
+	}
+	return assets
+}
+
 // GetAssetTypes gets available asset types
 func (in *SystemParameters) GetAssetTypes() []string {
-	switch {
-	case strings.HasPrefix(in.Type, "kubernetes"):
-		return []string{SystemAssetTypeHelmValues}
-	case in.Type == "custom":
-		return []string{SystemAssetTypeOpaConfig}
+	assets := in.GetAssets()
+	types := make([]string, len(assets))
+	for i, a := range assets {
+		types[i] = a.Type
 	}
+	return types
+}
 
-	return []string{}
+// GetKey gets the connection detail key of the asset.
+func (in *SystemAsset) GetKey() string {
+	if in.Key != nil && *in.Key != "" {
+		return *in.Key
+	}
+	return strcase.ToLowerCamel(in.Type)
+}
+
+// GetEncoding gets the encoding of the asset.
+func (in *SystemAsset) GetEncoding() string {
+	if in.Encoding != nil && *in.Encoding != "" {
+		return *in.Encoding
+	}
+	return SystemAssetEncodingRaw
+}
+
+// GetTypeInfo gets the SystemTypeInfo of the system type.
+func (in *SystemParameters) GetTypeInfo() SystemTypeInfo {
+	info, _ := LookupSystemType(in.Type)
+	return info
+}
+
+// HasLabels whether labels can be managed for the system type
+func (in *SystemParameters) HasLabels() bool {
+	return in.GetTypeInfo().Labels
 }
 
 // HasAssets whether the system has available assets
@@ -160,8 +468,52 @@ func (in *System) GetLastPublishedConnectionDetailsCertNotAfter() time.Time {
 	return time.Time{}
 }
 
+// GetLastPublishedConnectionDetailsCertNotBefore gets the start of the
+// validity of the last published OPA certificate from
+// AnnotationLastPublishedConnectionDetailsCertNotBefore.
+func (in *System) GetLastPublishedConnectionDetailsCertNotBefore() time.Time {
+	if val, exists := in.GetAnnotations()[AnnotationLastPublishedConnectionDetailsCertNotBefore]; exists {
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return time.Time{}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// GetConnectionDetailsRenewalTime gets the time at which the connection
+// details must be published again because the last published OPA certificate
+// is about to expire. It returns the zero time if no certificate has been
+// published.
+func (in *System) GetConnectionDetailsRenewalTime() time.Time {
+	notAfter := in.GetLastPublishedConnectionDetailsCertNotAfter()
+	if notAfter.IsZero() {
+		return notAfter
+	}
+	if in.Spec.ForProvider.ConnectionDetailsRenewBefore == nil {
+		return notAfter
+	}
+	renewBefore := in.Spec.ForProvider.ConnectionDetailsRenewBefore.Duration
+	// A new certificate has the same lifetime as the last one. Renewing it
+	// before half its lifetime would publish the connection details on every
+	// observation.
+	if notBefore := in.GetLastPublishedConnectionDetailsCertNotBefore(); !notBefore.IsZero() && notBefore.Before(notAfter) {
+		if limit := notAfter.Sub(notBefore) / 2; renewBefore > limit {
+			renewBefore = limit
+		}
+	}
+	return notAfter.Add(-renewBefore)
+}
+
 // SetLastPublishedConnectionDetailsCertNotAfter sets the value of
 // AnnotationLastPublishedConnectionDetailsHash.
 func (in *System) SetLastPublishedConnectionDetailsCertNotAfter(val time.Time) {
 	meta.AddAnnotations(in, map[string]string{AnnotationLastPublishedConnectionDetailsCertNotAfter: val.UTC().Format(time.RFC3339)})
 }
+
+// SetLastPublishedConnectionDetailsCertNotBefore sets the value of
+// AnnotationLastPublishedConnectionDetailsCertNotBefore.
+func (in *System) SetLastPublishedConnectionDetailsCertNotBefore(val time.Time) {
+	meta.AddAnnotations(in, map[string]string{AnnotationLastPublishedConnectionDetailsCertNotBefore: val.UTC().Format(time.RFC3339)})
+}
@@ -18,9 +18,11 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type SystemModifier func(*System)
@@ -148,6 +150,24 @@ func TestGetAssetTypes(t *testing.T) {
 				[]string{"opa-config"},
 			},
 		},
+		"SelectedAssets": {
+			args: args{
+				cr: getSystem(
+					withSpec(SystemParameters{
+						CustomSystemParameters: CustomSystemParameters{
+							Assets: []SystemAsset{
+								{Type: "opa-discovery-config"},
+								{Type: "install-instructions"},
+							},
+						},
+						Type: "custom",
+					}),
+				),
+			},
+			want: want{
+				[]string{"opa-discovery-config", "install-instructions"},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -183,7 +203,7 @@ func TestHasLabels(t *testing.T) {
 				),
 			},
 			want: want{
-				false,
+				true,
 			},
 		},
 		"KubernetesSystemV2": {
@@ -207,7 +227,7 @@ func TestHasLabels(t *testing.T) {
 				),
 			},
 			want: want{
-				false,
+				true,
 			},
 		},
 		"OPASystem": {
@@ -219,7 +239,19 @@ func TestHasLabels(t *testing.T) {
 				),
 			},
 			want: want{
-				false,
+				true,
+			},
+		},
+		"TerraformSystem": {
+			args: args{
+				cr: getSystem(
+					withSpec(SystemParameters{
+						Type: "terraform",
+					}),
+				),
+			},
+			want: want{
+				true,
 			},
 		},
 	}
@@ -234,3 +266,98 @@ func TestHasLabels(t *testing.T) {
 		})
 	}
 }
+
+func TestSystemAssetGetKey(t *testing.T) {
+	key := "config"
+
+	cases := map[string]struct {
+		asset SystemAsset
+		want  string
+	}{
+		"DefaultKey": {
+			asset: SystemAsset{Type: "opa-discovery-config"},
+			want:  "opaDiscoveryConfig",
+		},
+		"CustomKey": {
+			asset: SystemAsset{Type: "opa-config", Key: &key},
+			want:  "config",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.asset.GetKey()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetConnectionDetailsRenewalTime(t *testing.T) {
+	notAfter := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		cr   *System
+		want time.Time
+	}{
+		"NoCertificate": {
+			cr:   getSystem(),
+			want: time.Time{},
+		},
+		"NoRenewBefore": {
+			cr: getSystem(func(s *System) {
+				s.SetLastPublishedConnectionDetailsCertNotAfter(notAfter)
+			}),
+			want: notAfter,
+		},
+		"RenewBefore": {
+			cr: getSystem(
+				withSpec(SystemParameters{
+					CustomSystemParameters: CustomSystemParameters{
+						ConnectionDetailsRenewBefore: &metav1.Duration{Duration: 72 * time.Hour},
+					},
+				}),
+				func(s *System) {
+					s.SetLastPublishedConnectionDetailsCertNotAfter(notAfter)
+				},
+			),
+			want: notAfter.Add(-72 * time.Hour),
+		},
+		"RenewBeforeWithinHalfLifetime": {
+			cr: getSystem(
+				withSpec(SystemParameters{
+					CustomSystemParameters: CustomSystemParameters{
+						ConnectionDetailsRenewBefore: &metav1.Duration{Duration: 72 * time.Hour},
+					},
+				}),
+				func(s *System) {
+					s.SetLastPublishedConnectionDetailsCertNotBefore(notAfter.Add(-30 * 24 * time.Hour))
+					s.SetLastPublishedConnectionDetailsCertNotAfter(notAfter)
+				},
+			),
+			want: notAfter.Add(-72 * time.Hour),
+		},
+		"RenewBeforeExceedsHalfLifetime": {
+			cr: getSystem(
+				withSpec(SystemParameters{
+					CustomSystemParameters: CustomSystemParameters{
+						ConnectionDetailsRenewBefore: &metav1.Duration{Duration: 72 * time.Hour},
+					},
+				}),
+				func(s *System) {
+					s.SetLastPublishedConnectionDetailsCertNotBefore(notAfter.Add(-48 * time.Hour))
+					s.SetLastPublishedConnectionDetailsCertNotAfter(notAfter)
+				},
+			),
+			want: notAfter.Add(-24 * time.Hour),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.cr.GetConnectionDetailsRenewalTime()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"strings"
+)
+
+// Known Styra system types.
+const (
+	SystemTypeKubernetesV1 = "kubernetes:v1"
+	SystemTypeKubernetesV2 = "kubernetes:v2"
+	SystemTypeCustom       = "custom"
+	SystemTypeEnvoy        = "envoy"
+	SystemTypeIstio        = "istio"
+	SystemTypeKongGateway  = "kong-gateway"
+	SystemTypeTerraform    = "terraform"
+	SystemTypeSSH          = "ssh"
+)
+
+// A SystemTypeInfo describes the capabilities of a Styra system type.
+// +kubebuilder:object:generate=false
+type SystemTypeInfo struct {
+	// Name of the system type.
+	Name string
+
+	// AssetTypes that are published as connection details by default.
+	AssetTypes []string
+
+	// Labels is true if labels can be managed for systems of this type.
+	Labels bool
+
+	// DeploymentParameters is true if the deployment parameters apply to
+	// systems of this type.
+	DeploymentParameters bool
+
+	// Agents is true if agents register with systems of this type.
+	Agents bool
+
+	// VolatileFields are the fields of each asset type that change on every
+	// call to the Styra API, e.g. issued tokens and certificates. Fields are
+	// paths separated by dots. A * matches every element of a list or map.
+	VolatileFields map[string][]string
+}
+
+var (
+	helmValuesVolatileFields = []string{
+		"opa.Cert",
+		"opa.CACert",
+		"opa.Key",
+	}
+
+	opaConfigVolatileFields = []string{
+		"services.*.credentials.bearer.token",
+		"services.*.credentials.client_tls.cert",
+		"services.*.credentials.client_tls.private_key",
+		"services.*.tls.ca_cert",
+	}
+
+	kubernetesSystemType = SystemTypeInfo{
+		AssetTypes:           []string{SystemAssetTypeHelmValues},
+		Labels:               true,
+		DeploymentParameters: true,
+		Agents:               true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeHelmValues: helmValuesVolatileFields,
+			SystemAssetTypeOpaConfig:  opaConfigVolatileFields,
+		},
+	}
+
+	// unknownSystemType is assumed for system types that are not registered.
+	// It does not restrict any feature so that new Styra system types can be
+	// used before they are registered.
+	unknownSystemType = SystemTypeInfo{
+		Labels:               true,
+		DeploymentParameters: true,
+		Agents:               true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeHelmValues: helmValuesVolatileFields,
+			SystemAssetTypeOpaConfig:  opaConfigVolatileFields,
+		},
+	}
+)
+
+// systemTypes is the registry of all known Styra system types.
+var systemTypes = map[string]SystemTypeInfo{
+	SystemTypeKubernetesV1: kubernetesSystemType,
+	SystemTypeKubernetesV2: kubernetesSystemType,
+	SystemTypeCustom: {
+		AssetTypes:           []string{SystemAssetTypeOpaConfig},
+		Labels:               true,
+		DeploymentParameters: true,
+		Agents:               true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeOpaConfig: opaConfigVolatileFields,
+		},
+	},
+	SystemTypeEnvoy: {
+		Labels:               true,
+		DeploymentParameters: true,
+		Agents:               true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeOpaConfig: opaConfigVolatileFields,
+		},
+	},
+	SystemTypeIstio: {
+		Labels:               true,
+		DeploymentParameters: true,
+		Agents:               true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeOpaConfig: opaConfigVolatileFields,
+		},
+	},
+	SystemTypeKongGateway: {
+		Labels: true,
+		Agents: true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeOpaConfig: opaConfigVolatileFields,
+		},
+	},
+	// Terraform plans are validated by the Styra CLI. No agents register
+	// with terraform systems.
+	SystemTypeTerraform: {
+		Labels: true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeOpaConfig: opaConfigVolatileFields,
+		},
+	},
+	SystemTypeSSH: {
+		Labels: true,
+		Agents: true,
+		VolatileFields: map[string][]string{
+			SystemAssetTypeOpaConfig: opaConfigVolatileFields,
+		},
+	},
+}
+
+// LookupSystemType returns the SystemTypeInfo of the given system type and
+// whether the type is known. Unknown versions of a known type, e.g.
+// kubernetes:v3, get the capabilities of the latest known version. All other
+// unknown types get capabilities that do not restrict any feature.
+func LookupSystemType(name string) (SystemTypeInfo, bool) {
+	if info, ok := systemTypes[name]; ok {
+		info.Name = name
+		return info, true
+	}
+
+	info := unknownSystemType
+	family := strings.SplitN(name, ":", 2)[0]
+	latest := ""
+	for known, i := range systemTypes {
+		if strings.SplitN(known, ":", 2)[0] == family && known > latest {
+			info, latest = i, known
+		}
+	}
+	info.Name = name
+	return info, false
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLookupSystemType(t *testing.T) {
+	type want struct {
+		assetTypes           []string
+		deploymentParameters bool
+		agents               bool
+		known                bool
+	}
+
+	cases := map[string]struct {
+		systemType string
+		want
+	}{
+		"KubernetesV2": {
+			systemType: "kubernetes:v2",
+			want: want{
+				assetTypes:           []string{"helm-values"},
+				deploymentParameters: true,
+				agents:               true,
+				known:                true,
+			},
+		},
+		"Custom": {
+			systemType: "custom",
+			want: want{
+				assetTypes:           []string{"opa-config"},
+				deploymentParameters: true,
+				agents:               true,
+				known:                true,
+			},
+		},
+		"Terraform": {
+			systemType: "terraform",
+			want: want{
+				known: true,
+			},
+		},
+		"UnknownKubernetesVersion": {
+			systemType: "kubernetes:v123",
+			want: want{
+				assetTypes:           []string{"helm-values"},
+				deploymentParameters: true,
+				agents:               true,
+			},
+		},
+		"UnknownType": {
+			systemType: "fooType",
+			want: want{
+				deploymentParameters: true,
+				agents:               true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			info, known := LookupSystemType(tc.systemType)
+			got := want{
+				assetTypes:           info.AssetTypes,
+				deploymentParameters: info.DeploymentParameters,
+				agents:               info.Agents,
+				known:                known,
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if !info.Labels {
+				t.Errorf("LookupSystemType(%q): labels are not supported", tc.systemType)
+			}
+			if info.Name != tc.systemType {
+				t.Errorf("LookupSystemType(%q): want name %q, got %q", tc.systemType, tc.systemType, info.Name)
+			}
+		})
+	}
+}
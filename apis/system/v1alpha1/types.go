@@ -16,6 +16,11 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
 // V1DatasourceConfig v1 datasource config
 type V1DatasourceConfig struct {
 
@@ -40,8 +45,17 @@ type V1DatasourceConfig struct {
 
 // V1Status v1 status
 type V1Status struct {
-	// authz migration
-	AuthzMigration *string `json:"authz_migration,omitempty"`
+	// code of the status, e.g. finished or failed
+	// +optional
+	Code string `json:"code,omitempty"`
+
+	// message of the status
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// time of the last execution of the datasource
+	// +optional
+	Timestamp *metav1.Time `json:"timestamp,omitempty"`
 }
 
 // V1SystemDeploymentParameters v1 system deployment parameters
@@ -50,8 +64,10 @@ type V1SystemDeploymentParameters struct {
 	// true to fail close
 	DenyOnOpaFail *bool `json:"denyOnOpaFail,omitempty"`
 
-	// // extra deployment settings
-	// Extra interface{} `json:"extra,omitempty"`
+	// extra deployment settings that are specific to the system type
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Extra *runtime.RawExtension `json:"extra,omitempty"`
 
 	// HTTP proxy URL
 	HTTPProxy *string `json:"httpProxy,omitempty"`
@@ -77,3 +93,31 @@ type V1SystemDeploymentParameters struct {
 	// trusted container registry
 	TrustedContainerRegistry *string `json:"trustedContainerRegistry,omitempty"`
 }
+
+// V1SourceControlConfig v1 source control config
+type V1SourceControlConfig struct {
+
+	// origin
+	// +kubebuilder:validation:Required
+	Origin V1GitRepoConfig `json:"origin"`
+}
+
+// V1GitRepoConfig v1 git repo config
+type V1GitRepoConfig struct {
+
+	// Credentials are looked under the key <name>/<creds>
+	// +kubebuilder:validation:Required
+	Credentials string `json:"credentials"`
+
+	// Path to limit the import to
+	// +kubebuilder:validation:Required
+	Path string `json:"path"`
+
+	// Remote reference, defaults to refs/heads/master
+	// +kubebuilder:validation:Required
+	Reference string `json:"reference"`
+
+	// Repository URL
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+}
@@ -22,7 +22,9 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	apisv1alpha1 "github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -35,6 +37,45 @@ func (in *CustomSystemParameters) DeepCopyInto(out *CustomSystemParameters) {
 			(*out)[key] = val
 		}
 	}
+	if in.Assets != nil {
+		in, out := &in.Assets, &out.Assets
+		*out = make([]SystemAsset, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ConnectionDetailsRenewBefore != nil {
+		in, out := &in.ConnectionDetailsRenewBefore, &out.ConnectionDetailsRenewBefore
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.BundleDeployment != nil {
+		in, out := &in.BundleDeployment, &out.BundleDeployment
+		*out = new(SystemBundleDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BundleInterval != nil {
+		in, out := &in.BundleInterval, &out.BundleInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.AgentsInterval != nil {
+		in, out := &in.AgentsInterval, &out.AgentsInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(SystemValidation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make(map[string]SystemPolicy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomSystemParameters.
@@ -74,6 +115,135 @@ func (in *System) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemAgent) DeepCopyInto(out *SystemAgent) {
+	*out = *in
+	if in.LastSeen != nil {
+		in, out := &in.LastSeen, &out.LastSeen
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemAgent.
+func (in *SystemAgent) DeepCopy() *SystemAgent {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemAgent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemAgentsObservation) DeepCopyInto(out *SystemAgentsObservation) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SystemAgent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastObservedAt != nil {
+		in, out := &in.LastObservedAt, &out.LastObservedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemAgentsObservation.
+func (in *SystemAgentsObservation) DeepCopy() *SystemAgentsObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemAgentsObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemAsset) DeepCopyInto(out *SystemAsset) {
+	*out = *in
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
+		**out = **in
+	}
+	if in.Encoding != nil {
+		in, out := &in.Encoding, &out.Encoding
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemAsset.
+func (in *SystemAsset) DeepCopy() *SystemAsset {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemAsset)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemBundleDeployment) DeepCopyInto(out *SystemBundleDeployment) {
+	*out = *in
+	if in.BundleID != nil {
+		in, out := &in.BundleID, &out.BundleID
+		*out = new(string)
+		**out = **in
+	}
+	if in.Force != nil {
+		in, out := &in.Force, &out.Force
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemBundleDeployment.
+func (in *SystemBundleDeployment) DeepCopy() *SystemBundleDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemBundleDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemBundleObservation) DeepCopyInto(out *SystemBundleObservation) {
+	*out = *in
+	if in.CompiledAt != nil {
+		in, out := &in.CompiledAt, &out.CompiledAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDeployedAt != nil {
+		in, out := &in.LastDeployedAt, &out.LastDeployedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.CompileErrors != nil {
+		in, out := &in.CompileErrors, &out.CompileErrors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastObservedAt != nil {
+		in, out := &in.LastObservedAt, &out.LastObservedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemBundleObservation.
+func (in *SystemBundleObservation) DeepCopy() *SystemBundleObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemBundleObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SystemList) DeepCopyInto(out *SystemList) {
 	*out = *in
@@ -120,6 +290,21 @@ func (in *SystemObservation) DeepCopyInto(out *SystemObservation) {
 			}
 		}
 	}
+	if in.Agents != nil {
+		in, out := &in.Agents, &out.Agents
+		*out = new(SystemAgentsObservation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bundle != nil {
+		in, out := &in.Bundle, &out.Bundle
+		*out = new(SystemBundleObservation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(SystemValidationObservation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemObservation.
@@ -156,6 +341,11 @@ func (in *SystemParameters) DeepCopyInto(out *SystemParameters) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SourceControl != nil {
+		in, out := &in.SourceControl, &out.SourceControl
+		*out = new(V1SourceControlConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemParameters.
@@ -168,6 +358,53 @@ func (in *SystemParameters) DeepCopy() *SystemParameters {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemPolicy) DeepCopyInto(out *SystemPolicy) {
+	*out = *in
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make(map[string]SystemPolicyModule, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemPolicy.
+func (in *SystemPolicy) DeepCopy() *SystemPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemPolicyModule) DeepCopyInto(out *SystemPolicyModule) {
+	*out = *in
+	if in.Rego != nil {
+		in, out := &in.Rego, &out.Rego
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(apisv1alpha1.ConfigMapKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemPolicyModule.
+func (in *SystemPolicyModule) DeepCopy() *SystemPolicyModule {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemPolicyModule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SystemSpec) DeepCopyInto(out *SystemSpec) {
 	*out = *in
@@ -202,6 +439,55 @@ func (in *SystemStatus) DeepCopy() *SystemStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemValidation) DeepCopyInto(out *SystemValidation) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemValidation.
+func (in *SystemValidation) DeepCopy() *SystemValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemValidationObservation) DeepCopyInto(out *SystemValidationObservation) {
+	*out = *in
+	if in.LastValidatedAt != nil {
+		in, out := &in.LastValidatedAt, &out.LastValidatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FailedTests != nil {
+		in, out := &in.FailedTests, &out.FailedTests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemValidationObservation.
+func (in *SystemValidationObservation) DeepCopy() *SystemValidationObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemValidationObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *V1DatasourceConfig) DeepCopyInto(out *V1DatasourceConfig) {
 	*out = *in
@@ -222,13 +508,43 @@ func (in *V1DatasourceConfig) DeepCopy() *V1DatasourceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *V1GitRepoConfig) DeepCopyInto(out *V1GitRepoConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new V1GitRepoConfig.
+func (in *V1GitRepoConfig) DeepCopy() *V1GitRepoConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(V1GitRepoConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *V1SourceControlConfig) DeepCopyInto(out *V1SourceControlConfig) {
+	*out = *in
+	out.Origin = in.Origin
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new V1SourceControlConfig.
+func (in *V1SourceControlConfig) DeepCopy() *V1SourceControlConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(V1SourceControlConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *V1Status) DeepCopyInto(out *V1Status) {
 	*out = *in
-	if in.AuthzMigration != nil {
-		in, out := &in.AuthzMigration, &out.AuthzMigration
-		*out = new(string)
-		**out = **in
+	if in.Timestamp != nil {
+		in, out := &in.Timestamp, &out.Timestamp
+		*out = (*in).DeepCopy()
 	}
 }
 
@@ -250,6 +566,11 @@ func (in *V1SystemDeploymentParameters) DeepCopyInto(out *V1SystemDeploymentPara
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Extra != nil {
+		in, out := &in.Extra, &out.Extra
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.HTTPProxy != nil {
 		in, out := &in.HTTPProxy, &out.HTTPProxy
 		*out = new(string)
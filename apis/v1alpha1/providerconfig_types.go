@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	"reflect"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -37,11 +38,136 @@ type ProviderConfigSpec struct {
 	// Basepath of the Styra API. Defaults to "/"
 	// +optional
 	Basepath *string `json:"basepath,omitempty"`
+
+	// TLS settings used to connect to the Styra API.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// Proxy used to connect to the Styra API. If not set, the HTTPS_PROXY,
+	// HTTP_PROXY and NO_PROXY environment variables of the provider are used.
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+
+	// Retry settings for Styra API operations that failed with a transient
+	// error. Only idempotent operations are retried.
+	// +optional
+	Retry *RetryConfig `json:"retry,omitempty"`
+
+	// RateLimit limits the rate of requests that are sent to the Styra API.
+	// The limit is shared by all ProviderConfigs of the same tenant, i.e. with
+	// the same host and basepath, including those that do not set a rate
+	// limit. If the ProviderConfigs of a tenant set different limits the
+	// lowest QPS and burst are used. Requests are not limited if no
+	// ProviderConfig of the tenant sets a rate limit.
+	// +optional
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+	// Debug logs every request that is sent to the Styra API together with
+	// its response. Tokens, secret values and private keys are redacted.
+	// Logging can also be enabled for a single managed resource with the
+	// styra.crossplane.io/debug: "true" annotation.
+	// +optional
+	Debug bool `json:"debug,omitempty"`
+}
+
+// ProxyConfig configures an outbound HTTP proxy.
+type ProxyConfig struct {
+	// URL of the proxy, e.g. http://proxy.example.com:3128
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+
+	// NoProxy is a list of hosts that are not connected through the proxy.
+	// Entries follow the format of the NO_PROXY environment variable.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+
+	// CredentialsSecretRef references a secret containing the keys username
+	// and password that are used to authenticate against the proxy.
+	// +optional
+	CredentialsSecretRef *xpv1.SecretReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// TLSConfig configures the TLS connection to the Styra API.
+type TLSConfig struct {
+	// CABundleSecretRef references a secret key containing PEM encoded CA
+	// certificates that are trusted in addition to the system roots.
+	// +optional
+	CABundleSecretRef *xpv1.SecretKeySelector `json:"caBundleSecretRef,omitempty"`
+
+	// CABundleConfigMapRef references a config map key containing PEM encoded
+	// CA certificates that are trusted in addition to the system roots.
+	// +optional
+	CABundleConfigMapRef *ConfigMapKeySelector `json:"caBundleConfigMapRef,omitempty"`
+
+	// ClientCertSecretRef references a secret key containing the PEM encoded
+	// client certificate used for mutual TLS. Requires ClientKeySecretRef.
+	// +optional
+	ClientCertSecretRef *xpv1.SecretKeySelector `json:"clientCertSecretRef,omitempty"`
+
+	// ClientKeySecretRef references a secret key containing the PEM encoded
+	// private key of the client certificate. Requires ClientCertSecretRef.
+	// +optional
+	ClientKeySecretRef *xpv1.SecretKeySelector `json:"clientKeySecretRef,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the server certificate.
+	// This should only be used for testing.
+	// +optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// RetryConfig configures how failed Styra API operations are retried.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries of an operation. Set to 0
+	// to disable retries. Defaults to 3.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int `json:"maxRetries,omitempty"`
+
+	// InitialBackoff is the delay before the first retry. It is doubled for
+	// every following retry. Defaults to 500ms.
+	// +optional
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+
+	// MaxBackoff is the maximum delay between two retries. It also limits the
+	// delay requested by a Retry-After header. Defaults to 30s.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+}
+
+// RateLimitConfig configures a token bucket rate limiter.
+type RateLimitConfig struct {
+	// QPS is the number of requests per second that are sent to the Styra
+	// API on average. It must be greater than zero and may be a fraction,
+	// e.g. 500m for one request every two seconds.
+	// +kubebuilder:validation:Required
+	QPS resource.Quantity `json:"qps"`
+
+	// Burst is the maximum number of requests that are sent at once.
+	// Defaults to QPS rounded up.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst *int `json:"burst,omitempty"`
+}
+
+// A ConfigMapKeySelector is a reference to a config map key in an arbitrary
+// namespace.
+type ConfigMapKeySelector struct {
+	// Name of the config map.
+	Name string `json:"name"`
+
+	// Namespace of the config map.
+	Namespace string `json:"namespace"`
+
+	// The key to select.
+	Key string `json:"key"`
 }
 
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
 	// Source of the provider credentials.
+	// InjectedIdentity reads the token from the STYRA_TOKEN environment
+	// variable or the file /var/run/secrets/styra.com/token of the provider
+	// pod. None sends requests without an Authorization header.
 	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem
 	Source xpv1.CredentialsSource `json:"source"`
 
@@ -51,11 +177,21 @@ type ProviderCredentials struct {
 // A ProviderConfigStatus represents the status of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// LastCheckTime is the time the connection to Styra was last checked.
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// LastCheckLatency is the latency observed during the last connection
+	// check.
+	// +optional
+	LastCheckLatency *metav1.Duration `json:"lastCheckLatency,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 
 // A ProviderConfig configures how Styra controllers will connect to Styra API.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,provider,styra}
@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+const (
+	errGetReferencedObject = "cannot get %s %s/%s referenced by ProviderConfig"
+)
+
+// connections is the ConnectionCache shared by all controllers.
+var connections = NewConnectionCache()
+
+// A ConnectionCache caches the HTTP clients and credentials built from
+// ProviderConfigs so that connections to Styra are reused across reconciles.
+type ConnectionCache struct {
+	mu      sync.Mutex
+	entries map[types.UID]*connection
+}
+
+type connection struct {
+	name       string
+	key        string
+	httpClient *http.Client
+	token      string
+}
+
+// close releases the idle connections of the outdated client.
+func (c *connection) close() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// NewConnectionCache returns an empty ConnectionCache.
+func NewConnectionCache() *ConnectionCache {
+	return &ConnectionCache{
+		entries: map[types.UID]*connection{},
+	}
+}
+
+// Get returns the cached connection of pc. A new connection is built if none
+// is cached or if pc or any object it references has changed since the cached
+// connection was built.
+func (cc *ConnectionCache) Get(ctx context.Context, c client.Client, pc *v1alpha1.ProviderConfig) (*connection, error) {
+	key, err := connectionCacheKey(ctx, c, pc)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	conn, exists := cc.entries[pc.UID]
+	if exists && conn.key != key {
+		// Do not keep the client and credentials of an outdated
+		// ProviderConfig around, even if the new connection cannot be
+		// built.
+		delete(cc.entries, pc.UID)
+		conn.close()
+	}
+	cc.mu.Unlock()
+	if exists && conn.key == key {
+		return conn, nil
+	}
+
+	conn, err = newConnection(ctx, c, pc)
+	if err != nil {
+		return nil, err
+	}
+	conn.name = pc.GetName()
+	conn.key = key
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if old, exists := cc.entries[pc.UID]; exists {
+		old.close()
+	}
+	for uid, old := range cc.entries {
+		// A ProviderConfig of the same name has been deleted and created
+		// again.
+		if old.name == conn.name && uid != pc.UID {
+			delete(cc.entries, uid)
+			old.close()
+		}
+	}
+	cc.entries[pc.UID] = conn
+	return conn, nil
+}
+
+// Evict removes the cached connection of the ProviderConfig with the given
+// name, e.g. because it has been deleted.
+func (cc *ConnectionCache) Evict(name string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	for uid, conn := range cc.entries {
+		if conn.name == name {
+			delete(cc.entries, uid)
+			conn.close()
+		}
+	}
+}
+
+// EvictConnection removes the connection of the ProviderConfig with the given
+// name from the cache shared by all controllers. Its rate limit no longer
+// applies to the other ProviderConfigs of the tenant.
+func EvictConnection(name string) {
+	connections.Evict(name)
+	limiters.Forget(name)
+}
+
+// connectionCacheKey identifies the state of pc and all objects it references.
+// It changes whenever one of them is updated.
+func connectionCacheKey(ctx context.Context, c client.Client, pc *v1alpha1.ProviderConfig) (string, error) { // nolint:gocyclo
+	// Use the generation instead of the resource version of pc because its
+	// status is updated frequently.
+	parts := []string{string(pc.UID), strconv.FormatInt(pc.Generation, 10)}
+
+	creds := pc.Spec.Credentials
+	switch creds.Source { // nolint:exhaustive
+	case xpv1.CredentialsSourceSecret:
+		if creds.SecretRef != nil {
+			v, err := secretVersion(ctx, c, creds.SecretRef.Namespace, creds.SecretRef.Name)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, v)
+		}
+	case xpv1.CredentialsSourceEnvironment, xpv1.CredentialsSourceFilesystem, xpv1.CredentialsSourceInjectedIdentity:
+		// These sources have no resource version. Use a digest of the token
+		// instead so that rotated tokens are picked up.
+		pcreds, err := extractCredentials(ctx, c, creds)
+		if err != nil {
+			return "", errors.Wrap(err, errExtractCredentials)
+		}
+		sum := sha256.Sum256([]byte(pcreds.token))
+		parts = append(parts, hex.EncodeToString(sum[:]))
+	}
+
+	if pc.Spec.TLS != nil {
+		for _, ref := range []*xpv1.SecretKeySelector{pc.Spec.TLS.CABundleSecretRef, pc.Spec.TLS.ClientCertSecretRef, pc.Spec.TLS.ClientKeySecretRef} {
+			if ref == nil {
+				continue
+			}
+			v, err := secretVersion(ctx, c, ref.Namespace, ref.Name)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, v)
+		}
+		if ref := pc.Spec.TLS.CABundleConfigMapRef; ref != nil {
+			cm := &corev1.ConfigMap{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+				return "", errors.Wrapf(err, errGetReferencedObject, "config map", ref.Namespace, ref.Name)
+			}
+			parts = append(parts, cm.ResourceVersion)
+		}
+	}
+
+	if pc.Spec.Proxy != nil && pc.Spec.Proxy.CredentialsSecretRef != nil {
+		v, err := secretVersion(ctx, c, pc.Spec.Proxy.CredentialsSecretRef.Namespace, pc.Spec.Proxy.CredentialsSecretRef.Name)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, v)
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
+func secretVersion(ctx context.Context, c client.Client, namespace, name string) (string, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return "", errors.Wrapf(err, errGetReferencedObject, "secret", namespace, name)
+	}
+	return secret.ResourceVersion, nil
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+// newTestCertificate returns a PEM encoded self-signed CA certificate and its
+// private key.
+func newTestCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "styra-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// versionedKube returns a client that serves the token secret and the CA
+// bundle config map with the resource versions stored in versions.
+func versionedKube(caPEM []byte, versions map[string]string) client.Client {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key types.NamespacedName, obj client.Object) error {
+			switch o := obj.(type) {
+			case *corev1.Secret:
+				o.ResourceVersion = versions[key.Name]
+				o.Data = map[string][]byte{"token": []byte("styra-token")}
+			case *corev1.ConfigMap:
+				o.ResourceVersion = versions[key.Name]
+				o.Data = map[string]string{"ca.crt": string(caPEM)}
+			}
+			return nil
+		},
+	}
+}
+
+func testProviderConfig(uid types.UID, generation int64) *v1alpha1.ProviderConfig {
+	pc := &v1alpha1.ProviderConfig{
+		Spec: v1alpha1.ProviderConfigSpec{
+			Host: "styra.example.com",
+			Credentials: v1alpha1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{
+						SecretReference: xpv1.SecretReference{Name: "token", Namespace: "styra"},
+						Key:             "token",
+					},
+				},
+			},
+			TLS: &v1alpha1.TLSConfig{
+				CABundleConfigMapRef: &v1alpha1.ConfigMapKeySelector{Name: "ca", Namespace: "styra", Key: "ca.crt"},
+			},
+		},
+	}
+	pc.SetName("default")
+	pc.SetUID(uid)
+	pc.SetGeneration(generation)
+	return pc
+}
+
+func TestConnectionCacheGet(t *testing.T) {
+	caPEM, _ := newTestCertificate(t)
+
+	type state struct {
+		pc       *v1alpha1.ProviderConfig
+		versions map[string]string
+	}
+
+	cases := map[string]struct {
+		first  state
+		second state
+		reused bool
+	}{
+		"CacheHit": {
+			first:  state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			second: state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			reused: true,
+		},
+		"SecretChanged": {
+			first:  state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			second: state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "2", "ca": "1"}},
+			reused: false,
+		},
+		"ConfigMapChanged": {
+			first:  state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			second: state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "2"}},
+			reused: false,
+		},
+		"GenerationChanged": {
+			first:  state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			second: state{pc: testProviderConfig("a", 2), versions: map[string]string{"token": "1", "ca": "1"}},
+			reused: false,
+		},
+		"Recreated": {
+			first:  state{pc: testProviderConfig("a", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			second: state{pc: testProviderConfig("b", 1), versions: map[string]string{"token": "1", "ca": "1"}},
+			reused: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cc := NewConnectionCache()
+			first, err := cc.Get(context.Background(), versionedKube(caPEM, tc.first.versions), tc.first.pc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := cc.Get(context.Background(), versionedKube(caPEM, tc.second.versions), tc.second.pc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.reused, first == second); diff != "" {
+				t.Errorf("reused: -want, +got:\n%s", diff)
+			}
+			// Outdated connections must not be kept.
+			if diff := cmp.Diff(1, len(cc.entries)); diff != "" {
+				t.Errorf("entries: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConnectionCacheEvict(t *testing.T) {
+	caPEM, _ := newTestCertificate(t)
+	kube := versionedKube(caPEM, map[string]string{"token": "1", "ca": "1"})
+
+	cc := NewConnectionCache()
+	first, err := cc.Get(context.Background(), kube, testProviderConfig("a", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cc.Evict("other")
+	if diff := cmp.Diff(1, len(cc.entries)); diff != "" {
+		t.Errorf("entries after evicting another ProviderConfig: -want, +got:\n%s", diff)
+	}
+
+	cc.Evict("default")
+	if diff := cmp.Diff(0, len(cc.entries)); diff != "" {
+		t.Errorf("entries after evicting the ProviderConfig: -want, +got:\n%s", diff)
+	}
+
+	second, err := cc.Get(context.Background(), kube, testProviderConfig("a", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("Get returned an evicted connection")
+	}
+}
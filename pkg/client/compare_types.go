@@ -1,5 +1,11 @@
 package client
 
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
 // IsEqualString determinetes if StringValue's of two pointers are equal.
 func IsEqualString(s1 *string, s2 *string) bool {
 	return StringValue(s1) == StringValue(s2)
@@ -35,3 +41,14 @@ func IsEqualBool(b1 *bool, b2 *bool) bool {
 func IsEqualInt64(i1 *int64, i2 *int64) bool {
 	return Int64Value(i1) == Int64Value(i2)
 }
+
+// IsEqualJSON determines if two JSON documents are semantically equal,
+// regardless of formatting and the order of object keys. Documents that
+// cannot be parsed are compared byte by byte.
+func IsEqualJSON(j1 []byte, j2 []byte) bool {
+	var v1, v2 interface{}
+	if json.Unmarshal(j1, &v1) != nil || json.Unmarshal(j2, &v2) != nil {
+		return bytes.Equal(j1, j2)
+	}
+	return reflect.DeepEqual(v1, v2)
+}
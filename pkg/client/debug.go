@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	httptransport "github.com/go-openapi/runtime/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// AnnotationKeyDebug enables debug logging of all Styra API requests that are
+// sent for a managed resource if set to "true".
+const AnnotationKeyDebug = "styra.crossplane.io/debug"
+
+const redacted = "REDACTED"
+
+var (
+	debugLogMu sync.RWMutex
+	debugLog   = logging.NewNopLogger()
+
+	// sensitiveKeys match keys of JSON objects and YAML documents whose
+	// values must not be logged.
+	sensitiveKeys = []string{"token", "password", "secret", "passphrase", "private_key", "privatekey", "ssh_key", "sshkey", "authorization", "credentials"}
+
+	bearerTokenRegex   = regexp.MustCompile(`(?i)(bearer\s+)[^\s"']+`)
+	privateKeyRegex    = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)
+	base64Regex        = regexp.MustCompile(`[A-Za-z0-9+/]{64,}={0,2}`)
+	sensitiveLineRegex = regexp.MustCompile(`(?im)^(\s*-?\s*"?[\w.-]*(?:` + strings.Join(sensitiveKeys, "|") + `)[\w.-]*"?\s*[:=]\s*).+$`)
+)
+
+// SetLogger sets the logger that debug logs of Styra API requests are written
+// to.
+func SetLogger(l logging.Logger) {
+	debugLogMu.Lock()
+	defer debugLogMu.Unlock()
+	debugLog = l
+}
+
+func getLogger() logging.Logger {
+	debugLogMu.RLock()
+	defer debugLogMu.RUnlock()
+	return debugLog
+}
+
+// WithDebugLogging logs every request sent by the transport and its response
+// to log. Sensitive values are redacted.
+func WithDebugLogging(log logging.Logger) TransportOption {
+	return func(rt *httptransport.Runtime) {
+		if _, ok := rt.Transport.(*debugRoundTripper); ok {
+			return
+		}
+		rt.Transport = &debugRoundTripper{
+			transport: rt.Transport,
+			log:       log,
+		}
+	}
+}
+
+// debugRoundTripper logs requests and responses.
+type debugRoundTripper struct {
+	transport http.RoundTripper
+	log       logging.Logger
+}
+
+// RoundTrip sends the request using the wrapped transport and logs it
+// together with its response.
+func (t *debugRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := peekBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	duration := time.Since(start)
+
+	kv := []interface{}{
+		"method", req.Method,
+		"path", req.URL.Path,
+		"duration", duration.String(),
+		"requestBody", redact(reqBody),
+	}
+	if err != nil {
+		t.log.Info("Styra API request failed", append(kv, "error", err)...)
+		return resp, err
+	}
+
+	respBody, err := peekBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	t.log.Info("Styra API request", append(kv, "status", resp.StatusCode, "responseBody", redact(respBody))...)
+	return resp, nil
+}
+
+// peekBody reads body and replaces it with a reader of the same content.
+func peekBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	*body = io.NopCloser(bytes.NewReader(b))
+	return b, err
+}
+
+// redact removes bearer tokens, secret values and private keys from a
+// request or response body.
+func redact(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var obj interface{}
+	if err := json.Unmarshal(body, &obj); err == nil {
+		if b, err := json.Marshal(redactJSON(obj)); err == nil {
+			body = b
+		}
+	} else {
+		// Assets such as helm values are YAML documents.
+		body = sensitiveLineRegex.ReplaceAll(body, []byte("${1}"+redacted))
+	}
+
+	body = privateKeyRegex.ReplaceAll(body, []byte(redacted))
+	body = base64Regex.ReplaceAllFunc(body, redactBase64PrivateKey)
+	body = bearerTokenRegex.ReplaceAll(body, []byte("${1}"+redacted))
+	return string(body)
+}
+
+// redactBase64PrivateKey redacts b if it is a base64 encoded PEM private key,
+// such as the OPA key in the helm values of kubernetes systems.
+func redactBase64PrivateKey(b []byte) []byte {
+	decoded, err := base64.StdEncoding.DecodeString(string(b))
+	if err != nil || !privateKeyRegex.Match(decoded) {
+		return b
+	}
+	return []byte(redacted)
+}
+
+// redactJSON replaces the values of all sensitive keys in obj.
+func redactJSON(obj interface{}) interface{} {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if isSensitiveKey(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactJSON(val)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return obj
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	"github.com/pkg/errors"
+
+	"github.com/mistermx/styra-go-client/pkg/models"
+)
+
+// An ErrorReason classifies an error returned by the Styra API.
+type ErrorReason string
+
+// Error reasons.
+const (
+	ErrorReasonRetryable    ErrorReason = "Retryable"
+	ErrorReasonUnauthorized ErrorReason = "Unauthorized"
+	ErrorReasonInvalid      ErrorReason = "Invalid"
+	ErrorReasonNotFound     ErrorReason = "NotFound"
+	ErrorReasonConflict     ErrorReason = "Conflict"
+	ErrorReasonUnknown      ErrorReason = "Unknown"
+)
+
+// hints tell users how to resolve errors of a reason.
+var hints = map[ErrorReason]string{
+	ErrorReasonRetryable:    "Styra is temporarily unavailable, the request will be retried",
+	ErrorReasonUnauthorized: "check the credentials and permissions of the ProviderConfig",
+	ErrorReasonInvalid:      "check the spec of the resource",
+	ErrorReasonConflict:     "the resource already exists or was changed concurrently",
+}
+
+// APIError is an error response of the Styra API.
+type APIError struct {
+	// Operation is the ID of the failed operation, e.g. GetSystem.
+	Operation string
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the error code reported by Styra.
+	Code string
+
+	// Message is the error message reported by Styra.
+	Message string
+
+	// Errors are additional error details reported by Styra.
+	Errors []string
+
+	// RequestID identifies the failed request in the Styra logs.
+	RequestID string
+
+	// err is the error returned by the generated client.
+	err error
+}
+
+// Error returns a message that includes the reason of the error.
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s failed: %s (status %d", e.Operation, e.Reason(), e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, ", code %s", e.Code)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, ", request ID %s", e.RequestID)
+	}
+	b.WriteString(")")
+
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if len(e.Errors) > 0 {
+		msg = strings.Join(append([]string{msg}, e.Errors...), "; ")
+	}
+	if msg != "" {
+		fmt.Fprintf(&b, ": %s", msg)
+	}
+
+	if hint, ok := hints[e.Reason()]; ok {
+		fmt.Fprintf(&b, ": %s", hint)
+	}
+	return b.String()
+}
+
+// Unwrap returns the error returned by the generated client so that its type
+// can still be matched with errors.As.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Reason classifies the error by its status code.
+func (e *APIError) Reason() ErrorReason {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusForbidden:
+		return ErrorReasonUnauthorized
+	case e.StatusCode == http.StatusBadRequest, e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrorReasonInvalid
+	case e.StatusCode == http.StatusNotFound:
+		return ErrorReasonNotFound
+	case e.StatusCode == http.StatusConflict, e.StatusCode == http.StatusPreconditionFailed:
+		return ErrorReasonConflict
+	case e.StatusCode == http.StatusTooManyRequests, e.StatusCode >= http.StatusInternalServerError:
+		return ErrorReasonRetryable
+	}
+	return ErrorReasonUnknown
+}
+
+// ReasonFor returns the reason of the APIError in the chain of err. It
+// returns ErrorReasonUnknown if there is none.
+func ReasonFor(err error) ErrorReason {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Reason()
+	}
+	return ErrorReasonUnknown
+}
+
+// IsNotFound returns whether err is a Styra API error with status 404.
+func IsNotFound(err error) bool {
+	return ReasonFor(err) == ErrorReasonNotFound
+}
+
+// IsConflict returns whether err is a Styra API error caused by a conflict.
+func IsConflict(err error) bool {
+	return ReasonFor(err) == ErrorReasonConflict
+}
+
+// newAPIError builds an APIError from a response and the error returned by
+// the generated client.
+func newAPIError(operation string, resp runtime.ClientResponse, body []byte, err error) *APIError {
+	e := &APIError{
+		Operation:  operation,
+		StatusCode: resp.Code(),
+		RequestID:  resp.GetHeader("X-Request-Id"),
+		err:        err,
+	}
+
+	payload := &models.MetaV1ErrorResponse{}
+	if json.Unmarshal(body, payload) != nil {
+		return e
+	}
+	e.Code = StringValue(payload.Code)
+	e.Message = StringValue(payload.Message)
+	e.Errors = payload.Errors
+	if payload.RequestID != "" {
+		e.RequestID = payload.RequestID
+	}
+	return e
+}
+
+// ErrorTransport is a runtime.ClientTransport that converts error responses
+// of the Styra API into an *APIError.
+type ErrorTransport struct {
+	transport runtime.ClientTransport
+}
+
+// NewErrorTransport wraps transport.
+func NewErrorTransport(transport runtime.ClientTransport) *ErrorTransport {
+	return &ErrorTransport{transport: transport}
+}
+
+// Submit the operation and convert its error response.
+func (t *ErrorTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	reader := op.Reader
+	op.Reader = &errorReader{requestReader: reader, operation: op.ID}
+	defer func() { op.Reader = reader }()
+
+	return t.transport.Submit(op)
+}
+
+// errorReader buffers error responses so that their body can be read both
+// by the original reader and to build an APIError.
+type errorReader struct {
+	// the original request reader
+	requestReader runtime.ClientResponseReader
+
+	// the ID of the operation
+	operation string
+}
+
+// ReadResponse reads the response using the original reader and converts
+// the returned error.
+func (r *errorReader) ReadResponse(resp runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	if resp.Code() < http.StatusBadRequest {
+		return r.requestReader.ReadResponse(resp, consumer)
+	}
+
+	body, err := io.ReadAll(resp.Body())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.requestReader.ReadResponse(&bufferedResponse{ClientResponse: resp, body: body}, consumer)
+	if err != nil {
+		return res, newAPIError(r.operation, resp, body, err)
+	}
+	return res, nil
+}
+
+// bufferedResponse is a runtime.ClientResponse whose body has been read
+// already.
+type bufferedResponse struct {
+	runtime.ClientResponse
+	body []byte
+}
+
+// Body returns a reader of the buffered body.
+func (r *bufferedResponse) Body() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(r.body))
+}
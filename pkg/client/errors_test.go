@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+)
+
+func TestErrorTransportSubmit(t *testing.T) {
+	type want struct {
+		err    *APIError
+		reason ErrorReason
+	}
+
+	cases := map[string]struct {
+		response *fakeResponse
+		want
+	}{
+		"Success": {
+			response: &fakeResponse{code: 200},
+			want:     want{reason: ErrorReasonUnknown},
+		},
+		"StyraErrorResponse": {
+			response: &fakeResponse{
+				code: 401,
+				body: `{"code":"unauthorized","message":"invalid token","errors":[],"request_id":"abc"}`,
+			},
+			want: want{
+				err: &APIError{
+					Operation:  "GetSystem",
+					StatusCode: 401,
+					Code:       "unauthorized",
+					Message:    "invalid token",
+					Errors:     []string{},
+					RequestID:  "abc",
+				},
+				reason: ErrorReasonUnauthorized,
+			},
+		},
+		"NoErrorBody": {
+			response: &fakeResponse{
+				code:    503,
+				headers: http.Header{"X-Request-Id": []string{"def"}},
+			},
+			want: want{
+				err: &APIError{
+					Operation:  "GetSystem",
+					StatusCode: 503,
+					RequestID:  "def",
+				},
+				reason: ErrorReasonRetryable,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ft := &fakeTransport{responses: []*fakeResponse{tc.response}}
+			_, err := NewErrorTransport(ft).Submit(&runtime.ClientOperation{
+				ID:      "GetSystem",
+				Method:  http.MethodGet,
+				Reader:  readByCode,
+				Context: context.Background(),
+			})
+
+			var got *APIError
+			errors.As(err, &got)
+			if diff := cmp.Diff(tc.want.err, got, cmpopts.IgnoreUnexported(APIError{})); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.reason, ReasonFor(err)); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if got != nil && !errors.Is(err, errBoom) {
+				t.Errorf("Submit(...): want error to wrap %v, got %v", errBoom, err)
+			}
+		})
+	}
+}
+
+func TestAPIErrorError(t *testing.T) {
+	cases := map[string]struct {
+		err  *APIError
+		want string
+	}{
+		"Full": {
+			err: &APIError{
+				Operation:  "CreateSystem",
+				StatusCode: 400,
+				Code:       "invalid_request",
+				Message:    "invalid system",
+				Errors:     []string{"name is required"},
+				RequestID:  "abc",
+			},
+			want: "CreateSystem failed: Invalid (status 400, code invalid_request, request ID abc): invalid system; name is required: check the spec of the resource",
+		},
+		"StatusOnly": {
+			err:  &APIError{Operation: "GetSystem", StatusCode: 404},
+			want: "GetSystem failed: NotFound (status 404): Not Found",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.err.Error()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "styra"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Total number of Styra API requests by operation and status code.",
+	}, []string{"provider_config", "operation", "code"})
+
+	requestDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of Styra API requests by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider_config", "operation"})
+
+	requestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "api",
+		Name:      "requests_in_flight",
+		Help:      "Number of Styra API requests that are currently being processed.",
+	}, []string{"provider_config", "operation"})
+
+	rateLimitQPS = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "rate_limit",
+		Name:      "qps",
+		Help:      "Configured number of Styra API requests per second.",
+	}, []string{"host", "basepath"})
+
+	rateLimitBurst = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "rate_limit",
+		Name:      "burst",
+		Help:      "Configured burst of Styra API requests.",
+	}, []string{"host", "basepath"})
+
+	rateLimitWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "rate_limit",
+		Name:      "wait_seconds",
+		Help:      "Time Styra API requests waited for the rate limiter.",
+		Buckets:   []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"host", "basepath"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		requestsTotal,
+		requestDurationSeconds,
+		requestsInFlight,
+		rateLimitQPS,
+		rateLimitBurst,
+		rateLimitWaitSeconds,
+	)
+}
+
+// MetricsTransport is a runtime.ClientTransport that records metrics of all
+// operations that are submitted.
+type MetricsTransport struct {
+	transport      runtime.ClientTransport
+	providerConfig string
+}
+
+// NewMetricsTransport wraps transport and labels its metrics with the name
+// of the ProviderConfig.
+func NewMetricsTransport(transport runtime.ClientTransport, providerConfig string) *MetricsTransport {
+	return &MetricsTransport{
+		transport:      transport,
+		providerConfig: providerConfig,
+	}
+}
+
+// Submit the operation and record its status code and latency.
+func (t *MetricsTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	inFlight := requestsInFlight.WithLabelValues(t.providerConfig, op.ID)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	reader := op.Reader
+	recorder := &responseRecorder{requestReader: reader}
+	op.Reader = recorder
+	defer func() { op.Reader = reader }()
+
+	start := time.Now()
+	res, err := t.transport.Submit(op)
+	requestDurationSeconds.WithLabelValues(t.providerConfig, op.ID).Observe(time.Since(start).Seconds())
+	requestsTotal.WithLabelValues(t.providerConfig, op.ID, statusLabel(recorder.code)).Inc()
+	return res, err
+}
+
+// statusLabel returns the label of a status code. A code of 0 means that no
+// response has been received.
+func statusLabel(code int) string {
+	if code == 0 {
+		return "error"
+	}
+	return strconv.Itoa(code)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsTransportSubmit(t *testing.T) {
+	cases := map[string]struct {
+		pc        string
+		responses []*fakeResponse
+		want      map[string]float64
+	}{
+		"Success": {
+			pc:        "success",
+			responses: []*fakeResponse{{code: 200}},
+			want:      map[string]float64{"200": 1, "404": 0},
+		},
+		"NotFound": {
+			pc:        "not-found",
+			responses: []*fakeResponse{{code: 404}},
+			want:      map[string]float64{"200": 0, "404": 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ft := &fakeTransport{responses: tc.responses}
+			_, _ = NewMetricsTransport(ft, tc.pc).Submit(&runtime.ClientOperation{
+				ID:      "GetSystem",
+				Method:  http.MethodGet,
+				Reader:  readByCode,
+				Context: context.Background(),
+			})
+
+			got := map[string]float64{}
+			for code := range tc.want {
+				got[code] = testutil.ToFloat64(requestsTotal.WithLabelValues(tc.pc, "GetSystem", code))
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(0.0, testutil.ToFloat64(requestsInFlight.WithLabelValues(tc.pc, "GetSystem"))); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
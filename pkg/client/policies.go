@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mistermx/styra-go-client/pkg/client/policies"
+)
+
+// IsPolicyNotFound returns whether the given error is of type
+// GetPolicyNotFound or any other Styra API error with status 404.
+func IsPolicyNotFound(err error) bool {
+	var pnf *policies.GetPolicyNotFound
+	return errors.As(err, &pnf) || IsNotFound(err)
+}
+
+// GetPolicyModule returns the rego of the given module of a policy. It
+// returns false if the policy has no such module or the response is not
+// shaped as expected.
+func GetPolicyModule(resp *policies.GetPolicyOK, module string) (string, bool) {
+	modules, ok := GetPolicyModules(resp)
+	if !ok {
+		return "", false
+	}
+	rego, ok := modules[module]
+	return rego, ok
+}
+
+// GetPolicyModules returns the rego of all modules of a policy by their name.
+// It returns false if the response is not shaped as expected.
+func GetPolicyModules(resp *policies.GetPolicyOK) (map[string]string, bool) {
+	if resp == nil || resp.Payload == nil {
+		return nil, false
+	}
+	result, ok := resp.Payload.Result.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	raw, ok := result["modules"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	modules := make(map[string]string, len(raw))
+	for name, v := range raw {
+		rego, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		modules[name] = rego
+	}
+	return modules, true
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+const (
+	errRateLimit    = "cannot wait for rate limiter"
+	errRateLimitQPS = "rate limit qps must be greater than zero"
+)
+
+// limiters is the RateLimiterRegistry shared by all controllers.
+var limiters = NewRateLimiterRegistry()
+
+// A Tenant identifies a Styra tenant by the host and base path of its API.
+type Tenant struct {
+	Host     string
+	Basepath string
+}
+
+// tenantBucket is the rate limiter of a tenant together with the rate limits
+// of its ProviderConfigs it has been built from.
+type tenantBucket struct {
+	limiter *rate.Limiter
+	configs map[string]*v1alpha1.RateLimitConfig
+}
+
+// A RateLimiterRegistry holds one token bucket rate limiter per Styra tenant.
+// The limiter is shared by all ProviderConfigs of the tenant.
+type RateLimiterRegistry struct {
+	mu      sync.Mutex
+	tenants map[Tenant]*tenantBucket
+}
+
+// NewRateLimiterRegistry returns an empty RateLimiterRegistry.
+func NewRateLimiterRegistry() *RateLimiterRegistry {
+	return &RateLimiterRegistry{
+		tenants: map[Tenant]*tenantBucket{},
+	}
+}
+
+// Set records cfg as the rate limit of the named ProviderConfig of tenant and
+// updates the limiter of tenant. A nil cfg removes the rate limit of the
+// ProviderConfig. If the ProviderConfigs of a tenant set different limits the
+// lowest QPS and burst are used.
+func (r *RateLimiterRegistry) Set(tenant Tenant, providerConfig string, cfg *v1alpha1.RateLimitConfig) error {
+	if cfg != nil && cfg.QPS.Sign() <= 0 {
+		return errors.New(errRateLimitQPS)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A ProviderConfig may have been moved to another tenant.
+	for t := range r.tenants {
+		if t != tenant {
+			r.remove(t, providerConfig)
+		}
+	}
+	if cfg == nil {
+		r.remove(tenant, providerConfig)
+		return nil
+	}
+
+	b, exists := r.tenants[tenant]
+	if !exists {
+		b = &tenantBucket{configs: map[string]*v1alpha1.RateLimitConfig{}}
+		r.tenants[tenant] = b
+	}
+	b.configs[providerConfig] = cfg
+	r.update(tenant, b)
+	return nil
+}
+
+// Get returns the rate limiter of tenant or nil if no ProviderConfig of
+// tenant sets a rate limit.
+func (r *RateLimiterRegistry) Get(tenant Tenant) *rate.Limiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if b, exists := r.tenants[tenant]; exists {
+		return b.limiter
+	}
+	return nil
+}
+
+// Forget removes the rate limit of the named ProviderConfig from all tenants.
+func (r *RateLimiterRegistry) Forget(providerConfig string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for t := range r.tenants {
+		r.remove(t, providerConfig)
+	}
+}
+
+// remove the rate limit of providerConfig from tenant. The limiter of tenant
+// is dropped once no ProviderConfig sets a rate limit anymore.
+func (r *RateLimiterRegistry) remove(tenant Tenant, providerConfig string) {
+	b, exists := r.tenants[tenant]
+	if !exists {
+		return
+	}
+	if _, exists := b.configs[providerConfig]; !exists {
+		return
+	}
+	delete(b.configs, providerConfig)
+	if len(b.configs) == 0 {
+		delete(r.tenants, tenant)
+		rateLimitQPS.DeleteLabelValues(tenant.Host, tenant.Basepath)
+		rateLimitBurst.DeleteLabelValues(tenant.Host, tenant.Basepath)
+		return
+	}
+	r.update(tenant, b)
+}
+
+// update the limiter of tenant to the lowest limits of its ProviderConfigs.
+func (r *RateLimiterRegistry) update(tenant Tenant, b *tenantBucket) {
+	limit := rate.Inf
+	burst := math.MaxInt
+	for _, cfg := range b.configs {
+		qps := cfg.QPS.AsApproximateFloat64()
+		if l := rate.Limit(qps); l < limit {
+			limit = l
+		}
+		// Allow at least one request even if less than one request per
+		// second is sent on average.
+		cb := int(math.Max(1, math.Ceil(qps)))
+		if cfg.Burst != nil {
+			cb = *cfg.Burst
+		}
+		if cb < burst {
+			burst = cb
+		}
+	}
+
+	if b.limiter == nil {
+		b.limiter = rate.NewLimiter(limit, burst)
+	} else if b.limiter.Limit() != limit || b.limiter.Burst() != burst {
+		b.limiter.SetLimit(limit)
+		b.limiter.SetBurst(burst)
+	}
+
+	rateLimitQPS.WithLabelValues(tenant.Host, tenant.Basepath).Set(float64(limit))
+	rateLimitBurst.WithLabelValues(tenant.Host, tenant.Basepath).Set(float64(burst))
+}
+
+// RateLimitTransport is a runtime.ClientTransport that waits for the rate
+// limiter of a tenant before an operation is submitted.
+type RateLimitTransport struct {
+	transport runtime.ClientTransport
+	limiters  *RateLimiterRegistry
+	tenant    Tenant
+}
+
+// NewRateLimitTransport records the rate limit cfg of the named
+// ProviderConfig and wraps transport with the rate limiter of tenant.
+// Operations are limited whenever any ProviderConfig of the tenant sets a
+// rate limit, even if cfg is nil.
+func NewRateLimitTransport(transport runtime.ClientTransport, tenant Tenant, providerConfig string, cfg *v1alpha1.RateLimitConfig) (runtime.ClientTransport, error) {
+	if err := limiters.Set(tenant, providerConfig, cfg); err != nil {
+		return nil, err
+	}
+	return &RateLimitTransport{
+		transport: transport,
+		limiters:  limiters,
+		tenant:    tenant,
+	}, nil
+}
+
+// Submit the operation once the rate limiter allows it.
+func (t *RateLimitTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	// The limiter is looked up on every operation because another
+	// ProviderConfig of the tenant may have set a rate limit in the meantime.
+	l := t.limiters.Get(t.tenant)
+	if l == nil {
+		return t.transport.Submit(op)
+	}
+
+	ctx := op.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	start := time.Now()
+	if err := l.Wait(ctx); err != nil {
+		return nil, errors.Wrap(err, errRateLimit)
+	}
+	rateLimitWaitSeconds.WithLabelValues(t.tenant.Host, t.tenant.Basepath).Observe(time.Since(start).Seconds())
+
+	return t.transport.Submit(op)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+func rateLimit(qps string, burst *int) *v1alpha1.RateLimitConfig {
+	return &v1alpha1.RateLimitConfig{QPS: resource.MustParse(qps), Burst: burst}
+}
+
+func TestRateLimiterRegistry(t *testing.T) {
+	burst := 20
+	tenant := Tenant{Host: "styra.example.com", Basepath: "/"}
+	other := Tenant{Host: "styra.example.com", Basepath: "/other"}
+
+	// A step either sets the rate limit of a ProviderConfig or forgets it.
+	type step struct {
+		tenant         Tenant
+		providerConfig string
+		cfg            *v1alpha1.RateLimitConfig
+		forget         bool
+	}
+	type limits struct {
+		limit rate.Limit
+		burst int
+	}
+	type want struct {
+		err    error
+		limits *limits
+	}
+
+	cases := map[string]struct {
+		steps []step
+		want  want
+	}{
+		"NotConfigured": {
+			steps: []step{{tenant: tenant, providerConfig: "a"}},
+		},
+		"BurstDefaultsToQPS": {
+			steps: []step{{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)}},
+			want:  want{limits: &limits{limit: 5, burst: 5}},
+		},
+		"FractionalQPS": {
+			steps: []step{{tenant: tenant, providerConfig: "a", cfg: rateLimit("500m", nil)}},
+			want:  want{limits: &limits{limit: 0.5, burst: 1}},
+		},
+		"InvalidQPS": {
+			steps: []step{{tenant: tenant, providerConfig: "a", cfg: rateLimit("0", nil)}},
+			want:  want{err: errors.New(errRateLimitQPS)},
+		},
+		"UpdatedLimits": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("10", &burst)},
+			},
+			want: want{limits: &limits{limit: 10, burst: 20}},
+		},
+		"LowestLimitsOfTenant": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("10", &burst)},
+				{tenant: tenant, providerConfig: "b", cfg: rateLimit("5", nil)},
+			},
+			want: want{limits: &limits{limit: 5, burst: 5}},
+		},
+		"SharedWithProviderConfigWithoutLimit": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{tenant: tenant, providerConfig: "b"},
+			},
+			want: want{limits: &limits{limit: 5, burst: 5}},
+		},
+		"LimitRemoved": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{tenant: tenant, providerConfig: "a"},
+			},
+		},
+		"RemainingLimitAfterRemoval": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{tenant: tenant, providerConfig: "b", cfg: rateLimit("10", nil)},
+				{tenant: tenant, providerConfig: "a"},
+			},
+			want: want{limits: &limits{limit: 10, burst: 10}},
+		},
+		"OtherTenant": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{tenant: other, providerConfig: "b", cfg: rateLimit("1", nil)},
+			},
+			want: want{limits: &limits{limit: 5, burst: 5}},
+		},
+		"MovedToOtherTenant": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{tenant: other, providerConfig: "a", cfg: rateLimit("5", nil)},
+			},
+		},
+		"Forgotten": {
+			steps: []step{
+				{tenant: tenant, providerConfig: "a", cfg: rateLimit("5", nil)},
+				{providerConfig: "a", forget: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewRateLimiterRegistry()
+			var err error
+			for _, s := range tc.steps {
+				if s.forget {
+					r.Forget(s.providerConfig)
+					continue
+				}
+				err = r.Set(s.tenant, s.providerConfig, s.cfg)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+
+			var got *limits
+			if l := r.Get(tenant); l != nil {
+				got = &limits{limit: l.Limit(), burst: l.Burst()}
+			}
+			if diff := cmp.Diff(tc.want.limits, got, cmp.AllowUnexported(limits{})); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportSubmit(t *testing.T) {
+	tenant := Tenant{Host: "limited.example.com", Basepath: "/"}
+	burst := 1
+	defer limiters.Forget("limited")
+	defer limiters.Forget("unlimited")
+
+	// The transport of a ProviderConfig without rate limit is created before
+	// another ProviderConfig of the tenant sets one.
+	ft := &fakeTransport{responses: []*fakeResponse{{code: 200}, {code: 200}}}
+	transport, err := NewRateLimitTransport(ft, tenant, "unlimited", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRateLimitTransport(ft, tenant, "limited", rateLimit("100m", &burst)); err != nil {
+		t.Fatal(err)
+	}
+
+	submit := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err := transport.Submit(&runtime.ClientOperation{
+			ID:      "GetSystem",
+			Method:  http.MethodGet,
+			Reader:  readByCode,
+			Context: ctx,
+		})
+		return err
+	}
+
+	if err := submit(); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	// The bucket is empty and refills once every ten seconds, which exceeds
+	// the deadline of the request.
+	if err := submit(); err == nil {
+		t.Errorf("second request was not rate limited")
+	}
+	if diff := cmp.Diff(1, ft.calls); diff != "" {
+		t.Errorf("calls: -want, +got:\n%s", diff)
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"github.com/go-openapi/runtime"
+	httptransport "github.com/go-openapi/runtime/client"
 )
 
 // ForAllStatusCodes is a wildcard for all status codes
@@ -17,6 +18,15 @@ func DropContentTypeHeader(rt *runtime.ClientOperation) {
 	rt.ConsumesMediaTypes = nil
 }
 
+// DropDefaultMediaType makes requests without Content-Type header possible in
+// combination with DropContentTypeHeader.
+// It actually sets the header value to "", however, this is treated as not-set by Styra API.
+// Can be removed once https://github.com/go-openapi/runtime/issues/231 is resolved.
+func DropDefaultMediaType(rt *httptransport.Runtime) {
+	rt.DefaultMediaType = ""
+	rt.Producers[""] = nil
+}
+
 // ReturnRawResponse overwrites the default consumer to return the raw response body in bytes
 func ReturnRawResponse(rt *runtime.ClientOperation) {
 	OverwriteConsumer(runtime.ByteStreamConsumer())(rt)
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+// Default retry settings that are used if a ProviderConfig does not specify
+// them.
+const (
+	DefaultMaxRetries     = 3
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+)
+
+// RetryTransport is a runtime.ClientTransport that retries idempotent Styra
+// API operations that failed with a transient error.
+type RetryTransport struct {
+	transport      runtime.ClientTransport
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// NewRetryTransport wraps transport with the retry settings of a
+// ProviderConfig.
+func NewRetryTransport(transport runtime.ClientTransport, cfg *v1alpha1.RetryConfig) *RetryTransport {
+	t := &RetryTransport{
+		transport:      transport,
+		maxRetries:     DefaultMaxRetries,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+	}
+	if cfg == nil {
+		return t
+	}
+	if cfg.MaxRetries != nil {
+		t.maxRetries = *cfg.MaxRetries
+	}
+	if cfg.InitialBackoff != nil {
+		t.initialBackoff = cfg.InitialBackoff.Duration
+	}
+	if cfg.MaxBackoff != nil {
+		t.maxBackoff = cfg.MaxBackoff.Duration
+	}
+	return t
+}
+
+// Submit the operation and retry it if it is idempotent and has failed with
+// a transient error.
+func (t *RetryTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	if t.maxRetries <= 0 || !isIdempotent(op.Method) {
+		return t.transport.Submit(op)
+	}
+
+	ctx := op.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reader := op.Reader
+	for attempt := 0; ; attempt++ {
+		recorder := &responseRecorder{requestReader: reader}
+		op.Reader = recorder
+
+		res, err := t.transport.Submit(op)
+		if err == nil || attempt >= t.maxRetries || !isRetryable(ctx, recorder.code, err) {
+			op.Reader = reader
+			return res, err
+		}
+
+		delay := t.backoff(attempt, recorder.retryAfter)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			op.Reader = reader
+			return res, err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the given retry attempt. Retry-After takes
+// precedence over the jittered exponential backoff. Both are capped by
+// maxBackoff.
+func (t *RetryTransport) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		if retryAfter > t.maxBackoff {
+			return t.maxBackoff
+		}
+		return retryAfter
+	}
+
+	d := t.initialBackoff << uint(attempt)
+	if d <= 0 || d > t.maxBackoff {
+		d = t.maxBackoff
+	}
+	// Equal jitter: wait at least half of the backoff.
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) // nolint:gosec // No need for a secure random number.
+}
+
+// isIdempotent returns whether an operation with the given HTTP method can be
+// repeated safely.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryable returns whether a failed operation is worth retrying. A code of
+// 0 means that no response has been received.
+func isRetryable(ctx context.Context, code int, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch code {
+	case 0:
+		// Only retry network errors and not errors that occurred while
+		// building the request.
+		var uerr *url.Error
+		return errors.As(err, &uerr)
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// responseRecorder records the status code and Retry-After header of a
+// response before passing it on to the original reader.
+type responseRecorder struct {
+	// the original request reader
+	requestReader runtime.ClientResponseReader
+
+	// the response status code
+	code int
+
+	// the parsed value of the Retry-After header
+	retryAfter time.Duration
+}
+
+// ReadResponse records the response and reads it using the original reader.
+func (r *responseRecorder) ReadResponse(resp runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	r.code = resp.Code()
+	r.retryAfter = parseRetryAfter(resp.GetHeader("Retry-After"))
+	return r.requestReader.ReadResponse(resp, consumer)
+}
+
+// parseRetryAfter parses the value of a Retry-After header which is either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(val string) time.Duration {
+	if val == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(val); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+var errBoom = errors.New("boom")
+
+type fakeResponse struct {
+	code    int
+	headers http.Header
+	body    string
+}
+
+func (r *fakeResponse) Code() int                       { return r.code }
+func (r *fakeResponse) Message() string                 { return http.StatusText(r.code) }
+func (r *fakeResponse) GetHeader(name string) string    { return r.headers.Get(name) }
+func (r *fakeResponse) GetHeaders(name string) []string { return r.headers.Values(name) }
+func (r *fakeResponse) Body() io.ReadCloser             { return io.NopCloser(strings.NewReader(r.body)) }
+
+// fakeTransport answers with the given responses in order.
+type fakeTransport struct {
+	responses []*fakeResponse
+	calls     int
+}
+
+func (t *fakeTransport) Submit(op *runtime.ClientOperation) (interface{}, error) {
+	resp := t.responses[t.calls]
+	t.calls++
+	return op.Reader.ReadResponse(resp, nil)
+}
+
+var readByCode runtime.ClientResponseReaderFunc = func(resp runtime.ClientResponse, _ runtime.Consumer) (interface{}, error) {
+	if resp.Code() >= 300 {
+		return nil, errBoom
+	}
+	return resp.Code(), nil
+}
+
+func TestRetryTransportSubmit(t *testing.T) {
+	noDelay := &v1alpha1.RetryConfig{
+		InitialBackoff: &metav1.Duration{Duration: time.Millisecond},
+		MaxBackoff:     &metav1.Duration{Duration: time.Millisecond},
+	}
+
+	type want struct {
+		res   interface{}
+		err   error
+		calls int
+	}
+
+	cases := map[string]struct {
+		method    string
+		cfg       *v1alpha1.RetryConfig
+		responses []*fakeResponse
+		want
+	}{
+		"SuccessWithoutRetry": {
+			method:    http.MethodGet,
+			cfg:       noDelay,
+			responses: []*fakeResponse{{code: 200}},
+			want:      want{res: 200, calls: 1},
+		},
+		"RetryTooManyRequests": {
+			method: http.MethodGet,
+			cfg:    noDelay,
+			responses: []*fakeResponse{
+				{code: 429, headers: http.Header{"Retry-After": []string{"0"}}},
+				{code: 503},
+				{code: 200},
+			},
+			want: want{res: 200, calls: 3},
+		},
+		"GiveUpAfterMaxRetries": {
+			method: http.MethodPut,
+			cfg: &v1alpha1.RetryConfig{
+				MaxRetries:     func() *int { i := 1; return &i }(),
+				InitialBackoff: noDelay.InitialBackoff,
+				MaxBackoff:     noDelay.MaxBackoff,
+			},
+			responses: []*fakeResponse{{code: 503}, {code: 503}},
+			want:      want{err: errBoom, calls: 2},
+		},
+		"NoRetryForNonIdempotentMethod": {
+			method:    http.MethodPost,
+			cfg:       noDelay,
+			responses: []*fakeResponse{{code: 503}},
+			want:      want{err: errBoom, calls: 1},
+		},
+		"NoRetryForClientError": {
+			method:    http.MethodGet,
+			cfg:       noDelay,
+			responses: []*fakeResponse{{code: 404}},
+			want:      want{err: errBoom, calls: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ft := &fakeTransport{responses: tc.responses}
+			res, err := NewRetryTransport(ft, tc.cfg).Submit(&runtime.ClientOperation{
+				Method:  tc.method,
+				Reader:  readByCode,
+				Context: context.Background(),
+			})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.res, res); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, ft.calls); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := map[string]struct {
+		val  string
+		want time.Duration
+	}{
+		"Empty":   {val: "", want: 0},
+		"Seconds": {val: "5", want: 5 * time.Second},
+		"Invalid": {val: "soon", want: 0},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, parseRetryAfter(tc.val)); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
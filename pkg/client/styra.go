@@ -19,13 +19,15 @@ package client
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/go-openapi/runtime"
 	httptransport "github.com/go-openapi/runtime/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -38,26 +40,44 @@ const (
 	errNoProviderConfigRef            = "no providerConfigRef is given"
 	errCannotGetProvider              = "cannot get referenced Provider"
 	errCannotTrackProviderConfigUsage = "cannot track ProviderConfig usage"
-	errOnlySecretSourceAllowed        = "only Secret supported as Source"
-	errExtractSecret                  = "cannot extract credentials from secret"
-	errExtractSecretKey               = "cannot extract secret key"
-	errGetCredentialsSecret           = "cannot get credentials secret"
+	errExtractCredentials             = "cannot extract credentials"
+	errBuildHTTPClient                = "cannot build HTTP client"
 	errInvalidSecretData              = "'%s' is required in secret data"
+	errEmptyEnvCredentials            = "environment variable '%s' is empty or not set"
+	errEmptyFsCredentials             = "file '%s' is empty"
+	errNoInjectedIdentity             = "no token injected: neither environment variable '%s' nor file '%s' is set"
+	errEmptyCredentials               = "credentials of source '%s' are empty"
 )
 
-// GetConfig constructs an *httptransport.Runtime that can be used to connect to Styra
+// Well-known locations from which the token is read if the credentials
+// source is InjectedIdentity. The platform running the provider is expected
+// to inject the token into the provider pod at one of these locations.
+const (
+	InjectedIdentityTokenEnv  = "STYRA_TOKEN"
+	InjectedIdentityTokenPath = "/var/run/secrets/styra.com/token"
+)
+
+// injectedIdentityTokenPath is the file read by extractInjectedIdentity. It is
+// a variable so that tests can point it to a temporary file.
+var injectedIdentityTokenPath = InjectedIdentityTokenPath
+
+// A TransportOption modifies the *httptransport.Runtime that is built for a
+// single consumer before it is wrapped.
+type TransportOption func(*httptransport.Runtime)
+
+// GetConfig constructs a runtime.ClientTransport that can be used to connect to Styra
 // API by the Styra client.
-func GetConfig(ctx context.Context, c client.Client, mg resource.Managed) (*httptransport.Runtime, error) {
+func GetConfig(ctx context.Context, c client.Client, mg resource.Managed, opts ...TransportOption) (runtime.ClientTransport, error) {
 	switch {
 	case mg.GetProviderConfigReference() != nil:
-		return UseProviderConfig(ctx, c, mg)
+		return UseProviderConfig(ctx, c, mg, opts...)
 	default:
 		return nil, errors.New(errNoProviderConfigRef)
 	}
 }
 
-// UseProviderConfig to produce a *httptransport.Runtime that can be used to connect to Styra.
-func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed) (*httptransport.Runtime, error) {
+// UseProviderConfig to produce a runtime.ClientTransport that can be used to connect to Styra.
+func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed, opts ...TransportOption) (runtime.ClientTransport, error) {
 	pc := &v1alpha1.ProviderConfig{}
 	if err := c.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errCannotGetProvider)
@@ -68,13 +88,21 @@ func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed
 		return nil, errors.Wrap(err, errCannotTrackProviderConfigUsage)
 	}
 
-	if pc.Spec.Credentials.Source != xpv1.CredentialsSourceSecret {
-		return nil, errors.New(errOnlySecretSourceAllowed)
+	if mg.GetAnnotations()[AnnotationKeyDebug] == "true" {
+		opts = append(opts, WithDebugLogging(getLogger().WithValues("providerConfig", pc.GetName(), "resource", mg.GetName())))
 	}
 
-	creds, credsErr := extractCredentialsFromSecret(ctx, c, pc.Spec.Credentials.CommonCredentialSelectors)
-	if credsErr != nil {
-		return nil, errors.Wrap(credsErr, errExtractSecret)
+	return NewTransport(ctx, c, pc, opts...)
+}
+
+// NewTransport returns a runtime.ClientTransport that connects to Styra using
+// the settings of the given ProviderConfig. The underlying connections are
+// cached and shared until the ProviderConfig or one of the objects it
+// references changes.
+func NewTransport(ctx context.Context, c client.Client, pc *v1alpha1.ProviderConfig, opts ...TransportOption) (runtime.ClientTransport, error) {
+	conn, err := connections.Get(ctx, c, pc)
+	if err != nil {
+		return nil, err
 	}
 
 	basepath := StringValue(pc.Spec.Basepath)
@@ -82,37 +110,107 @@ func UseProviderConfig(ctx context.Context, c client.Client, mg resource.Managed
 		basepath = "/"
 	}
 
+	// Share the underlying connections but let every runtime build its own
+	// client so that options can wrap the transport.
 	transport := httptransport.New(pc.Spec.Host, basepath, styra.DefaultSchemes)
-	transport.DefaultAuthentication = httptransport.BearerToken(creds.token)
+	transport.Transport = conn.httpClient.Transport
+	if conn.token != "" {
+		transport.DefaultAuthentication = httptransport.BearerToken(conn.token)
+	}
+
+	if pc.Spec.Debug {
+		opts = append(opts, WithDebugLogging(getLogger().WithValues("providerConfig", pc.GetName())))
+	}
 
-	// Enable this line to see request and response in console output
-	// transport.SetDebug(true)
+	for _, o := range opts {
+		o(transport)
+	}
 
-	return transport, nil
+	// Every retry has to pass the rate limiter again and is recorded as a
+	// separate request.
+	instrumented := NewMetricsTransport(NewErrorTransport(transport), pc.GetName())
+	limited, err := NewRateLimitTransport(instrumented, Tenant{Host: pc.Spec.Host, Basepath: basepath}, pc.GetName(), pc.Spec.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	return NewRetryTransport(limited, pc.Spec.Retry), nil
+}
+
+// newConnection builds the *http.Client and reads the credentials used to
+// connect to Styra from the given ProviderConfig.
+func newConnection(ctx context.Context, c client.Client, pc *v1alpha1.ProviderConfig) (*connection, error) {
+	creds, credsErr := extractCredentials(ctx, c, pc.Spec.Credentials)
+	if credsErr != nil {
+		return nil, errors.Wrap(credsErr, errExtractCredentials)
+	}
+
+	httpClient, err := newHTTPClient(ctx, c, pc.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildHTTPClient)
+	}
+
+	return &connection{
+		httpClient: httpClient,
+		token:      creds.token,
+	}, nil
 }
 
 type providerCredentials struct {
 	token string
 }
 
-func extractCredentialsFromSecret(ctx context.Context, client client.Client, s xpv1.CommonCredentialSelectors) (*providerCredentials, error) {
-	if s.SecretRef == nil {
-		return nil, errors.New(errExtractSecretKey)
+// extractCredentials reads the Styra API token from the source configured in
+// the ProviderConfig.
+func extractCredentials(ctx context.Context, c client.Client, pcc v1alpha1.ProviderCredentials) (*providerCredentials, error) {
+	var (
+		token []byte
+		err   error
+	)
+	switch pcc.Source { // nolint:exhaustive
+	case xpv1.CredentialsSourceInjectedIdentity:
+		token, err = extractInjectedIdentity()
+	default:
+		token, err = resource.CommonCredentialExtractor(ctx, pcc.Source, c, pcc.CommonCredentialSelectors)
 	}
-	secret := &corev1.Secret{}
-	if err := client.Get(ctx, types.NamespacedName{Namespace: s.SecretRef.Namespace, Name: s.SecretRef.Name}, secret); err != nil {
-		return nil, errors.Wrap(err, errGetCredentialsSecret)
+	if err != nil {
+		return nil, err
 	}
 
-	token := secret.Data[s.SecretRef.Key]
-
-	if token == nil {
-		return nil, errors.New(fmt.Sprintf(errInvalidSecretData, s.SecretRef.Key))
+	// Tokens mounted from files or set in the environment commonly carry a
+	// trailing newline.
+	creds := &providerCredentials{
+		token: strings.TrimSpace(string(token)),
 	}
 
-	creds := &providerCredentials{
-		token: string(token),
+	if creds.token == "" && pcc.Source != xpv1.CredentialsSourceNone {
+		return nil, errEmptyToken(pcc)
 	}
 
 	return creds, nil
 }
+
+// extractInjectedIdentity reads the token that has been injected into the
+// provider pod by the environment.
+func extractInjectedIdentity() ([]byte, error) {
+	if token := os.Getenv(InjectedIdentityTokenEnv); token != "" {
+		return []byte(token), nil
+	}
+	token, err := os.ReadFile(injectedIdentityTokenPath)
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf(errNoInjectedIdentity, InjectedIdentityTokenEnv, injectedIdentityTokenPath)
+	}
+	return token, err
+}
+
+// errEmptyToken returns an error that names the misconfigured source location.
+func errEmptyToken(pcc v1alpha1.ProviderCredentials) error {
+	switch pcc.Source { // nolint:exhaustive
+	case xpv1.CredentialsSourceSecret:
+		return errors.New(fmt.Sprintf(errInvalidSecretData, pcc.SecretRef.Key))
+	case xpv1.CredentialsSourceEnvironment:
+		return errors.Errorf(errEmptyEnvCredentials, pcc.Env.Name)
+	case xpv1.CredentialsSourceFilesystem:
+		return errors.Errorf(errEmptyFsCredentials, pcc.Fs.Path)
+	}
+	return errors.Errorf(errEmptyCredentials, pcc.Source)
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	xperrors "github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+const testTokenEnv = "STYRA_TEST_TOKEN"
+
+func TestExtractCredentials(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	emptyFile := filepath.Join(dir, "empty")
+	missingFile := filepath.Join(dir, "missing")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(emptyFile, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secretKube := func(data map[string][]byte, err error) client.Client {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(err, func(obj client.Object) error {
+				obj.(*corev1.Secret).Data = data
+				return nil
+			}),
+		}
+	}
+	secretRef := xpv1.CommonCredentialSelectors{
+		SecretRef: &xpv1.SecretKeySelector{
+			SecretReference: xpv1.SecretReference{Name: "styra", Namespace: "crossplane-system"},
+			Key:             "token",
+		},
+	}
+
+	type args struct {
+		kube client.Client
+		pcc  v1alpha1.ProviderCredentials
+		// env holds the environment variables that are set for the case.
+		env map[string]string
+		// injectedPath is the file the injected identity token is read from.
+		injectedPath string
+	}
+	type want struct {
+		creds *providerCredentials
+		err   error
+	}
+
+	cases := map[string]struct {
+		args args
+		want want
+	}{
+		"InjectedIdentityFromEnv": {
+			args: args{
+				pcc:          v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceInjectedIdentity},
+				env:          map[string]string{InjectedIdentityTokenEnv: "env-token\n"},
+				injectedPath: tokenFile,
+			},
+			want: want{creds: &providerCredentials{token: "env-token"}},
+		},
+		"InjectedIdentityFromFile": {
+			args: args{
+				pcc:          v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceInjectedIdentity},
+				injectedPath: tokenFile,
+			},
+			want: want{creds: &providerCredentials{token: "file-token"}},
+		},
+		"InjectedIdentityMissing": {
+			args: args{
+				pcc:          v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceInjectedIdentity},
+				injectedPath: missingFile,
+			},
+			want: want{err: errors.Errorf(errNoInjectedIdentity, InjectedIdentityTokenEnv, missingFile)},
+		},
+		"InjectedIdentityEmpty": {
+			args: args{
+				pcc:          v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceInjectedIdentity},
+				injectedPath: emptyFile,
+			},
+			want: want{err: errors.Errorf(errEmptyCredentials, xpv1.CredentialsSourceInjectedIdentity)},
+		},
+		"Secret": {
+			args: args{
+				kube: secretKube(map[string][]byte{"token": []byte("secret-token")}, nil),
+				pcc: v1alpha1.ProviderCredentials{
+					Source:                    xpv1.CredentialsSourceSecret,
+					CommonCredentialSelectors: secretRef,
+				},
+			},
+			want: want{creds: &providerCredentials{token: "secret-token"}},
+		},
+		"SecretMissingKey": {
+			args: args{
+				kube: secretKube(map[string][]byte{"other": []byte("secret-token")}, nil),
+				pcc: v1alpha1.ProviderCredentials{
+					Source:                    xpv1.CredentialsSourceSecret,
+					CommonCredentialSelectors: secretRef,
+				},
+			},
+			want: want{err: errors.Errorf(errInvalidSecretData, "token")},
+		},
+		"SecretGetError": {
+			args: args{
+				kube: secretKube(nil, errBoom),
+				pcc: v1alpha1.ProviderCredentials{
+					Source:                    xpv1.CredentialsSourceSecret,
+					CommonCredentialSelectors: secretRef,
+				},
+			},
+			want: want{err: xperrors.Wrap(errBoom, "cannot get credentials secret")},
+		},
+		"Environment": {
+			args: args{
+				pcc: v1alpha1.ProviderCredentials{
+					Source: xpv1.CredentialsSourceEnvironment,
+					CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+						Env: &xpv1.EnvSelector{Name: testTokenEnv},
+					},
+				},
+				env: map[string]string{testTokenEnv: "env-token"},
+			},
+			want: want{creds: &providerCredentials{token: "env-token"}},
+		},
+		"EnvironmentEmpty": {
+			args: args{
+				pcc: v1alpha1.ProviderCredentials{
+					Source: xpv1.CredentialsSourceEnvironment,
+					CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+						Env: &xpv1.EnvSelector{Name: testTokenEnv},
+					},
+				},
+			},
+			want: want{err: errors.Errorf(errEmptyEnvCredentials, testTokenEnv)},
+		},
+		"Filesystem": {
+			args: args{
+				pcc: v1alpha1.ProviderCredentials{
+					Source: xpv1.CredentialsSourceFilesystem,
+					CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+						Fs: &xpv1.FsSelector{Path: tokenFile},
+					},
+				},
+			},
+			want: want{creds: &providerCredentials{token: "file-token"}},
+		},
+		"FilesystemEmpty": {
+			args: args{
+				pcc: v1alpha1.ProviderCredentials{
+					Source: xpv1.CredentialsSourceFilesystem,
+					CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+						Fs: &xpv1.FsSelector{Path: emptyFile},
+					},
+				},
+			},
+			want: want{err: errors.Errorf(errEmptyFsCredentials, emptyFile)},
+		},
+		"None": {
+			args: args{
+				pcc: v1alpha1.ProviderCredentials{Source: xpv1.CredentialsSourceNone},
+			},
+			want: want{creds: &providerCredentials{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(InjectedIdentityTokenEnv, "")
+			t.Setenv(testTokenEnv, "")
+			for k, v := range tc.args.env {
+				t.Setenv(k, v)
+			}
+			path := injectedIdentityTokenPath
+			injectedIdentityTokenPath = tc.args.injectedPath
+			defer func() { injectedIdentityTokenPath = path }()
+
+			creds, err := extractCredentials(context.Background(), tc.args.kube, tc.args.pcc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.creds, creds, cmp.AllowUnexported(providerCredentials{})); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
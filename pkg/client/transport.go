@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/pkg/errors"
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+)
+
+const (
+	errGetTLSSecret          = "cannot get TLS secret"
+	errGetTLSConfigMap       = "cannot get TLS config map"
+	errMissingKeyFmt         = "key '%s' not found in %s %s/%s"
+	errGetCABundle           = "cannot get CA bundle"
+	errInvalidCABundle       = "CA bundle does not contain any valid PEM encoded certificate"
+	errGetClientCert         = "cannot get client certificate"
+	errGetClientKey          = "cannot get client key"
+	errClientCertKeyRequired = "clientCertSecretRef and clientKeySecretRef must be set together"
+	errLoadClientKeyPair     = "cannot load client certificate key pair"
+	errSystemCertPool        = "cannot load system certificate pool"
+	errParseProxyURL         = "cannot parse proxy URL"
+	errGetProxySecret        = "cannot get proxy credentials secret"
+)
+
+// Keys of the proxy credentials secret.
+const (
+	ProxyCredentialsUsernameKey = "username"
+	ProxyCredentialsPasswordKey = "password"
+)
+
+// newHTTPClient constructs the *http.Client that is used by the Styra
+// transport of a ProviderConfig.
+func newHTTPClient(ctx context.Context, kube client.Client, spec v1alpha1.ProviderConfigSpec) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if spec.TLS != nil {
+		tlsConfig, err := newTLSConfig(ctx, kube, spec.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if spec.Proxy != nil {
+		proxy, err := newProxyFunc(ctx, kube, spec.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = proxy
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// newTLSConfig builds a *tls.Config from the TLS settings of a
+// ProviderConfig.
+func newTLSConfig(ctx context.Context, kube client.Client, cfg *v1alpha1.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: BoolValue(cfg.InsecureSkipVerify), // nolint:gosec // Explicitly requested by the user.
+	}
+
+	if cfg.CABundleSecretRef != nil || cfg.CABundleConfigMapRef != nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, errors.Wrap(err, errSystemCertPool)
+		}
+		for _, getBundle := range []func() ([]byte, error){
+			func() ([]byte, error) { return getSecretKey(ctx, kube, cfg.CABundleSecretRef) },
+			func() ([]byte, error) { return getConfigMapKey(ctx, kube, cfg.CABundleConfigMapRef) },
+		} {
+			bundle, err := getBundle()
+			if err != nil {
+				return nil, errors.Wrap(err, errGetCABundle)
+			}
+			if bundle != nil && !pool.AppendCertsFromPEM(bundle) {
+				return nil, errors.New(errInvalidCABundle)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (cfg.ClientCertSecretRef == nil) != (cfg.ClientKeySecretRef == nil) {
+		return nil, errors.New(errClientCertKeyRequired)
+	}
+	if cfg.ClientCertSecretRef != nil {
+		certPEM, err := getSecretKey(ctx, kube, cfg.ClientCertSecretRef)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetClientCert)
+		}
+		keyPEM, err := getSecretKey(ctx, kube, cfg.ClientKeySecretRef)
+		if err != nil {
+			return nil, errors.Wrap(err, errGetClientKey)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadClientKeyPair)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// getSecretKey returns the value of the referenced secret key or nil if no
+// reference is given.
+func getSecretKey(ctx context.Context, kube client.Client, ref *xpv1.SecretKeySelector) ([]byte, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, errors.Wrap(err, errGetTLSSecret)
+	}
+	val, exists := secret.Data[ref.Key]
+	if !exists {
+		return nil, errors.Errorf(errMissingKeyFmt, ref.Key, "secret", ref.Namespace, ref.Name)
+	}
+	return val, nil
+}
+
+// getConfigMapKey returns the value of the referenced config map key or nil
+// if no reference is given.
+func getConfigMapKey(ctx context.Context, kube client.Client, ref *v1alpha1.ConfigMapKeySelector) ([]byte, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return nil, errors.Wrap(err, errGetTLSConfigMap)
+	}
+	if val, exists := cm.Data[ref.Key]; exists {
+		return []byte(val), nil
+	}
+	if val, exists := cm.BinaryData[ref.Key]; exists {
+		return val, nil
+	}
+	return nil, errors.Errorf(errMissingKeyFmt, ref.Key, "config map", ref.Namespace, ref.Name)
+}
+
+// newProxyFunc returns a function that selects the proxy of a request
+// according to the proxy settings of a ProviderConfig.
+func newProxyFunc(ctx context.Context, kube client.Client, cfg *v1alpha1.ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	proxyURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseProxyURL)
+	}
+
+	if cfg.CredentialsSecretRef != nil {
+		secret := &corev1.Secret{}
+		if err := kube.Get(ctx, types.NamespacedName{Namespace: cfg.CredentialsSecretRef.Namespace, Name: cfg.CredentialsSecretRef.Name}, secret); err != nil {
+			return nil, errors.Wrap(err, errGetProxySecret)
+		}
+		username, exists := secret.Data[ProxyCredentialsUsernameKey]
+		if !exists {
+			return nil, errors.Errorf(errMissingKeyFmt, ProxyCredentialsUsernameKey, "secret", cfg.CredentialsSecretRef.Namespace, cfg.CredentialsSecretRef.Name)
+		}
+		proxyURL.User = url.UserPassword(string(username), string(secret.Data[ProxyCredentialsPasswordKey]))
+	}
+
+	// httpproxy handles the NO_PROXY matching rules for us. The proxy is used
+	// for both schemes because the Styra API may be served via plain HTTP.
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyURL.String(),
+		HTTPSProxy: proxyURL.String(),
+		NoProxy:    strings.Join(cfg.NoProxy, ","),
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
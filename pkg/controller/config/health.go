@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+	"github.com/crossplane-contrib/provider-styra/pkg/interface/controller"
+)
+
+const (
+	healthTimeout = 30 * time.Second
+
+	// secretRefsIndex indexes ProviderConfigs by the secrets they reference.
+	secretRefsIndex = "spec.secretRefs"
+
+	errGetPC           = "cannot get ProviderConfig"
+	errListPCs         = "cannot list ProviderConfigs"
+	errIndexSecretRefs = "cannot index ProviderConfigs by secret references"
+	errUpdateStatus    = "cannot update ProviderConfig status"
+	errBuildTransport  = "cannot build Styra transport"
+	errConnectionCheck = "cannot connect to Styra"
+)
+
+// SetupHealth adds a controller that periodically checks whether the Styra
+// API is reachable with the settings of a ProviderConfig and reports the
+// result in its Ready condition.
+func SetupHealth(mgr ctrl.Manager, o controller.Options) error {
+	name := "health/" + strings.ToLower(v1alpha1.ProviderConfigGroupKind)
+
+	r := &healthReconciler{
+		kube:           mgr.GetClient(),
+		newTransportFn: styraclient.NewTransport,
+		newClientFn:    styra.New,
+		interval:       o.PollInterval,
+		log:            o.Logger.WithValues("controller", name),
+	}
+
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.ProviderConfig{}, secretRefsIndex, indexSecretRefs); err != nil {
+		return errors.Wrap(err, errIndexSecretRefs)
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ProviderConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForSecret)).
+		Complete(r)
+}
+
+type healthReconciler struct {
+	kube           client.Client
+	newTransportFn func(ctx context.Context, kube client.Client, pc *v1alpha1.ProviderConfig, opts ...styraclient.TransportOption) (runtime.ClientTransport, error)
+	newClientFn    func(transport runtime.ClientTransport, formats strfmt.Registry) *styra.StyraAPI
+	interval       time.Duration
+	log            logging.Logger
+}
+
+// Reconcile checks the connection to Styra of a ProviderConfig.
+func (r *healthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout)
+	defer cancel()
+
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, req.NamespacedName, pc); err != nil {
+		if kerrors.IsNotFound(err) {
+			styraclient.EvictConnection(req.Name)
+		}
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPC)
+	}
+
+	if meta.WasDeleted(pc) {
+		styraclient.EvictConnection(pc.GetName())
+		return reconcile.Result{}, nil
+	}
+
+	orig := pc.DeepCopy()
+	start := time.Now()
+	err := r.check(ctx, pc)
+	latency := time.Since(start)
+
+	pc.Status.LastCheckTime = &metav1.Time{Time: start}
+	pc.Status.LastCheckLatency = &metav1.Duration{Duration: latency.Round(time.Millisecond)}
+	if err != nil {
+		log.Debug(errConnectionCheck, "error", err)
+		c := xpv1.Unavailable().WithMessage(err.Error())
+		if reason := styraclient.ReasonFor(err); reason != styraclient.ErrorReasonUnknown {
+			c.Reason = xpv1.ConditionReason(reason)
+		}
+		pc.SetConditions(c)
+	} else {
+		pc.SetConditions(xpv1.Available())
+	}
+
+	return reconcile.Result{RequeueAfter: r.interval}, errors.Wrap(r.kube.Status().Patch(ctx, pc, client.MergeFrom(orig)), errUpdateStatus)
+}
+
+// check calls a cheap authenticated Styra endpoint.
+func (r *healthReconciler) check(ctx context.Context, pc *v1alpha1.ProviderConfig) error {
+	transport, err := r.newTransportFn(ctx, r.kube, pc)
+	if err != nil {
+		return errors.Wrap(err, errBuildTransport)
+	}
+
+	_, err = r.newClientFn(transport, strfmt.Default).Systems.ListSystems(&systems.ListSystemsParams{
+		Context: ctx,
+		Compact: styraclient.Bool(true),
+	})
+	return errors.Wrap(err, errConnectionCheck)
+}
+
+// requestsForSecret enqueues all ProviderConfigs that reference the given
+// secret.
+func (r *healthReconciler) requestsForSecret(obj client.Object) []reconcile.Request {
+	l := &v1alpha1.ProviderConfigList{}
+	if err := r.kube.List(context.Background(), l, client.MatchingFields{secretRefsIndex: secretRefKey(obj.GetNamespace(), obj.GetName())}); err != nil {
+		r.log.Debug(errListPCs, "error", err)
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(l.Items))
+	for _, pc := range l.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: pc.GetName()}})
+	}
+	return reqs
+}
+
+// indexSecretRefs returns the keys of all secrets that the given
+// ProviderConfig references.
+func indexSecretRefs(obj client.Object) []string {
+	pc, ok := obj.(*v1alpha1.ProviderConfig)
+	if !ok {
+		return nil
+	}
+	spec := pc.Spec
+
+	var refs []xpv1.SecretReference
+	if spec.Credentials.SecretRef != nil {
+		refs = append(refs, spec.Credentials.SecretRef.SecretReference)
+	}
+	if spec.TLS != nil {
+		for _, s := range []*xpv1.SecretKeySelector{spec.TLS.CABundleSecretRef, spec.TLS.ClientCertSecretRef, spec.TLS.ClientKeySecretRef} {
+			if s != nil {
+				refs = append(refs, s.SecretReference)
+			}
+		}
+	}
+	if spec.Proxy != nil && spec.Proxy.CredentialsSecretRef != nil {
+		refs = append(refs, *spec.Proxy.CredentialsSecretRef)
+	}
+
+	keys := make([]string, 0, len(refs))
+	for _, s := range refs {
+		keys = append(keys, secretRefKey(s.Namespace, s.Name))
+	}
+	return keys
+}
+
+// secretRefKey returns the secretRefsIndex key of a secret.
+func secretRefKey(namespace, name string) string {
+	return namespace + "/" + name
+}
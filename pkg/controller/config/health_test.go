@@ -0,0 +1,310 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+
+	"github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+	mocksystem "github.com/crossplane-contrib/provider-styra/pkg/client/mock/systems"
+)
+
+var (
+	errBoom = errors.New("boom")
+
+	testInterval = time.Minute
+)
+
+func TestHealthReconcile(t *testing.T) {
+	type args struct {
+		kube         client.Client
+		transportErr error
+		systems      func(*mocksystem.MockClientService)
+	}
+
+	type want struct {
+		result reconcile.Result
+		err    error
+	}
+
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"Available": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						pc := obj.(*v1alpha1.ProviderConfig)
+						if pc.Status.LastCheckTime == nil || pc.Status.LastCheckLatency == nil {
+							return errors.New("last check not recorded")
+						}
+						if diff := cmp.Diff(xpv1.Available(), pc.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+							return errors.New(diff)
+						}
+						return nil
+					},
+				},
+				systems: func(mcs *mocksystem.MockClientService) {
+					mcs.EXPECT().
+						ListSystems(gomock.Any()).
+						Return(&systems.ListSystemsOK{}, nil)
+				},
+			},
+			want: want{
+				result: reconcile.Result{RequeueAfter: testInterval},
+			},
+		},
+		"UnavailableOnAPIError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						pc := obj.(*v1alpha1.ProviderConfig)
+						want := xpv1.Unavailable().WithMessage(errors.Wrap(errBoom, errConnectionCheck).Error())
+						if diff := cmp.Diff(want, pc.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+							return errors.New(diff)
+						}
+						return nil
+					},
+				},
+				systems: func(mcs *mocksystem.MockClientService) {
+					mcs.EXPECT().
+						ListSystems(gomock.Any()).
+						Return(nil, errBoom)
+				},
+			},
+			want: want{
+				result: reconcile.Result{RequeueAfter: testInterval},
+			},
+		},
+		"UnauthorizedOnAuthError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						pc := obj.(*v1alpha1.ProviderConfig)
+						if diff := cmp.Diff(xpv1.ConditionReason(styraclient.ErrorReasonUnauthorized), pc.GetCondition(xpv1.TypeReady).Reason); diff != "" {
+							return errors.New(diff)
+						}
+						return nil
+					},
+				},
+				systems: func(mcs *mocksystem.MockClientService) {
+					mcs.EXPECT().
+						ListSystems(gomock.Any()).
+						Return(nil, &styraclient.APIError{Operation: "ListSystems", StatusCode: 401})
+				},
+			},
+			want: want{
+				result: reconcile.Result{RequeueAfter: testInterval},
+			},
+		},
+		"UnavailableOnTransportError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						pc := obj.(*v1alpha1.ProviderConfig)
+						want := xpv1.Unavailable().WithMessage(errors.Wrap(errBoom, errBuildTransport).Error())
+						if diff := cmp.Diff(want, pc.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+							return errors.New(diff)
+						}
+						return nil
+					},
+				},
+				transportErr: errBoom,
+				systems:      func(mcs *mocksystem.MockClientService) {},
+			},
+			want: want{
+				result: reconcile.Result{RequeueAfter: testInterval},
+			},
+		},
+		"GetError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+				systems: func(mcs *mocksystem.MockClientService) {},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetPC),
+			},
+		},
+		"StatusPatchError": {
+			args: args{
+				kube: &test.MockClient{
+					MockGet:         test.NewMockGetFn(nil),
+					MockStatusPatch: test.NewMockStatusPatchFn(errBoom),
+				},
+				systems: func(mcs *mocksystem.MockClientService) {
+					mcs.EXPECT().
+						ListSystems(gomock.Any()).
+						Return(&systems.ListSystemsOK{}, nil)
+				},
+			},
+			want: want{
+				result: reconcile.Result{RequeueAfter: testInterval},
+				err:    errors.Wrap(errBoom, errUpdateStatus),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mock := mocksystem.NewMockClientService(gomock.NewController(t))
+			tc.args.systems(mock)
+
+			r := &healthReconciler{
+				kube: tc.args.kube,
+				newTransportFn: func(_ context.Context, _ client.Client, _ *v1alpha1.ProviderConfig, _ ...styraclient.TransportOption) (runtime.ClientTransport, error) {
+					return nil, tc.args.transportErr
+				},
+				newClientFn: func(_ runtime.ClientTransport, _ strfmt.Registry) *styra.StyraAPI {
+					return &styra.StyraAPI{Systems: mock}
+				},
+				interval: testInterval,
+				log:      logging.NewNopLogger(),
+			}
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIndexSecretRefs(t *testing.T) {
+	ref := xpv1.SecretReference{Namespace: "crossplane-system", Name: "styra-creds"}
+
+	cases := map[string]struct {
+		obj  client.Object
+		want []string
+	}{
+		"CredentialsSecret": {
+			obj: &v1alpha1.ProviderConfig{
+				Spec: v1alpha1.ProviderConfigSpec{
+					Credentials: v1alpha1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{SecretReference: ref, Key: "token"},
+						},
+					},
+				},
+			},
+			want: []string{"crossplane-system/styra-creds"},
+		},
+		"TLSAndProxySecrets": {
+			obj: &v1alpha1.ProviderConfig{
+				Spec: v1alpha1.ProviderConfigSpec{
+					TLS: &v1alpha1.TLSConfig{
+						ClientCertSecretRef: &xpv1.SecretKeySelector{SecretReference: ref, Key: "tls.crt"},
+						ClientKeySecretRef:  &xpv1.SecretKeySelector{SecretReference: ref, Key: "tls.key"},
+					},
+					Proxy: &v1alpha1.ProxyConfig{
+						CredentialsSecretRef: &xpv1.SecretReference{Namespace: "default", Name: "proxy"},
+					},
+				},
+			},
+			want: []string{"crossplane-system/styra-creds", "crossplane-system/styra-creds", "default/proxy"},
+		},
+		"NoReferences": {
+			obj:  &v1alpha1.ProviderConfig{},
+			want: []string{},
+		},
+		"NotAProviderConfig": {
+			obj:  &v1alpha1.ProviderConfigUsage{},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, indexSecretRefs(tc.obj)); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequestsForSecret(t *testing.T) {
+	secret := &corev1.Secret{}
+	secret.SetNamespace("crossplane-system")
+	secret.SetName("styra-creds")
+
+	cases := map[string]struct {
+		kube client.Client
+		want []reconcile.Request
+	}{
+		"ReferencingProviderConfigs": {
+			kube: &test.MockClient{
+				MockList: func(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					o := &client.ListOptions{}
+					o.ApplyOptions(opts)
+					if diff := cmp.Diff(secretRefsIndex+"=crossplane-system/styra-creds", o.FieldSelector.String()); diff != "" {
+						return errors.New(diff)
+					}
+					pc := v1alpha1.ProviderConfig{}
+					pc.SetName("default")
+					list.(*v1alpha1.ProviderConfigList).Items = []v1alpha1.ProviderConfig{pc}
+					return nil
+				},
+			},
+			want: []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "default"}}},
+		},
+		"ListError": {
+			kube: &test.MockClient{
+				MockList: test.NewMockListFn(errBoom),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &healthReconciler{kube: tc.kube, log: logging.NewNopLogger()}
+			if diff := cmp.Diff(tc.want, r.requestsForSecret(secret)); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -20,10 +20,13 @@ import (
 	"errors"
 
 	"github.com/mistermx/styra-go-client/pkg/client/datasources"
+
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
 )
 
-// isNotFound returns whether the given error is of type NotFound or not.
+// isNotFound returns whether the given error is of type NotFound or any
+// other Styra API error with status 404.
 func isNotFound(err error) bool {
 	var dnf *datasources.GetDatasourceNotFound
-	return errors.As(err, &dnf)
+	return errors.As(err, &dnf) || styraclient.IsNotFound(err)
 }
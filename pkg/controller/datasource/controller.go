@@ -86,7 +86,6 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 	}
 
 	cfg, err := styraclient.GetConfig(ctx, c.kube, mg)
-	// cfg.Debug = true
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +214,7 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 	}
 
 	if _, err := e.client.Datasources.DeleteDatasource(req); err != nil {
-		return errors.Wrap(err, errDeleteFailed)
+		return errors.Wrap(resource.Ignore(isNotFound, err), errDeleteFailed)
 	}
 
 	return nil
@@ -802,6 +802,28 @@ func TestDelete(t *testing.T) {
 				err: errors.Wrap(errBoom, errDeleteFailed),
 			},
 		},
+		"NotFound": {
+			args: args{
+				styra: styra.StyraAPI{
+					Datasources: withMockDataSource(t, func(mcs *mockdatasource.MockClientService) {
+						mcs.EXPECT().
+							DeleteDatasource(&datasources.DeleteDatasourceParams{
+								Datasource: testDataSourceID,
+								Context:    context.Background(),
+							}).
+							Return(nil, &styraclient.APIError{Operation: "DeleteDatasource", StatusCode: 404})
+					}),
+				},
+				cr: DataSource(
+					withExternalName(testDataSourceID),
+				),
+			},
+			want: want{
+				cr: DataSource(
+					withExternalName(testDataSourceID),
+				),
+			},
+		},
 	}
 
 	for name, tc := range cases {
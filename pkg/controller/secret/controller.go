@@ -103,16 +103,12 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.New(errNotSecret)
 	}
 
-	cfg, err := styraclient.GetConfig(ctx, c.kube, mg)
+	// Workaround to make a request without Content-Type header for DELETE.
+	cfg, err := styraclient.GetConfig(ctx, c.kube, mg, styraclient.DropDefaultMediaType)
 	if err != nil {
 		return nil, err
 	}
 
-	// Workaround to make a request without Content-Type header for DELETE.
-	// It actually sets the header value to "", however, this is treated as not-set by Styra API.
-	// Can be removed once https://github.com/go-openapi/runtime/issues/231 is resolved.
-	cfg.DefaultMediaType = ""
-	cfg.Producers[""] = nil
 	client := c.newClientFn(cfg, strfmt.Default)
 
 	applicator := &resource.ClientApplicator{
@@ -230,7 +226,7 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 	// Can be removed once https://github.com/go-openapi/runtime/issues/231 is resolved.
 	_, err := e.client.Secrets.DeleteSecret(req, styraclient.DropContentTypeHeader)
 
-	return errors.Wrap(err, errDeleteFailed)
+	return errors.Wrap(resource.Ignore(isNotFound, err), errDeleteFailed)
 }
 
 func (e *external) getSecret(ctx context.Context, cr *v1alpha1.Secret) (*secrets.GetSecretOK, error) {
@@ -1661,6 +1661,57 @@ func TestDelete(t *testing.T) {
 				err: errors.Wrap(errBoom, errDeleteFailed),
 			},
 		},
+		"NotFound": {
+			args: args{
+				kube: mockKube(
+					withMockKubeClient(func(mc *mockkube.MockClient) {
+						mc.EXPECT().Delete(
+							context.Background(),
+							&corev1.Secret{
+								ObjectMeta: metav1.ObjectMeta{
+									Name:      generateChecksumSecretName(testSecretID),
+									Namespace: testSecretRefNameSpace,
+								},
+							})
+					}),
+				),
+				styra: mockStyra(
+					withMockSecret(func(mcs *mocksecret.MockClientService) {
+						mcs.EXPECT().
+							DeleteSecret(
+								&secrets.DeleteSecretParams{
+									SecretID: testSecretID,
+									Context:  context.Background(),
+								},
+								gomock.Any(),
+							).
+							Return(nil, &styraclient.APIError{Operation: "DeleteSecret", StatusCode: 404})
+					}),
+				),
+				cr: Secret(
+					withExternalName(testSecretID),
+					withSpec(v1alpha1.SecretParameters{
+						ChecksumSecretRef: &v1alpha1.SecretReference{
+							Name:      generateChecksumSecretName(testSecretID),
+							Namespace: testSecretRefNameSpace,
+							Key:       styraclient.String(checksumSecretDefaultKey),
+						},
+					}),
+				),
+			},
+			want: want{
+				cr: Secret(
+					withExternalName(testSecretID),
+					withSpec(v1alpha1.SecretParameters{
+						ChecksumSecretRef: &v1alpha1.SecretReference{
+							Name:      generateChecksumSecretName(testSecretID),
+							Namespace: testSecretRefNameSpace,
+							Key:       styraclient.String(checksumSecretDefaultKey),
+						},
+					}),
+				),
+			},
+		},
 		"DeleteChecksumFailed": {
 			args: args{
 				kube: mockKube(
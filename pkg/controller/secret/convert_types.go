@@ -72,8 +72,9 @@ func generateSecretChecksum(secretVal string, t time.Time) (string, error) {
 	return string(sum[:]), nil
 }
 
-// isNotFound returns whether the given error is of type NotFound or not.
+// isNotFound returns whether the given error is of type NotFound or any
+// other Styra API error with status 404.
 func isNotFound(err error) bool {
 	var snf *secrets.GetSecretNotFound
-	return errors.As(err, &snf)
+	return errors.As(err, &snf) || styraclient.IsNotFound(err)
 }
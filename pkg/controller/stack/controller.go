@@ -45,16 +45,15 @@ import (
 )
 
 const (
-	errNotStack                    = "managed resource is not an Stack custom resource"
-	errUpdateFailed                = "cannot update Stack custom resource"
-	errCreateFailed                = "cannot create Stack"
-	errDeleteFailed                = "cannot delete Stack"
-	errDescribeFailed              = "cannot describe Stack"
-	errIsUpToDateFailed            = "isUpToDate failed"
-	errGetSelectors                = "cannot get system selectors"
-	errGetSelectorsInvalidResponse = "get system selectors returned an unexpected response"
-	errCompareSelectors            = "cannot compare selectors"
-	errUpdateSelectors             = "cannotUpdateselectors"
+	errNotStack         = "managed resource is not an Stack custom resource"
+	errUpdateFailed     = "cannot update Stack custom resource"
+	errCreateFailed     = "cannot create Stack"
+	errDeleteFailed     = "cannot delete Stack"
+	errDescribeFailed   = "cannot describe Stack"
+	errIsUpToDateFailed = "isUpToDate failed"
+	errGetSelectors     = "cannot get system selectors"
+	errCompareSelectors = "cannot compare selectors"
+	errUpdateSelectors  = "cannotUpdateselectors"
 )
 
 // SetupStack adds a controller that reconciles Stacks.
@@ -164,32 +163,18 @@ func (e *external) areSelectorsUpToDate(ctx context.Context, cr *v1alpha1.Stack)
 	}
 
 	res, err := e.client.Policies.GetPolicy(req)
+	if styraclient.IsPolicyNotFound(err) {
+		// The selectors policy has been deleted, e.g. in the Styra UI.
+		// Update creates it again.
+		return false, nil
+	}
 	if err != nil {
 		return false, errors.Wrap(err, errGetSelectors)
 	}
 
-	// Q&D solution to get raw rego. There may be a more elegant solution for this
-	result, ok := res.Payload.Result.(map[string]interface{})
-	if !ok {
-		return false, errors.New(errGetSelectorsInvalidResponse)
-	}
-
-	if _, exists := result["modules"]; !exists {
-		return false, errors.New(errGetSelectorsInvalidResponse)
-	}
-
-	modules, ok := result["modules"].(map[string]interface{})
+	selectorsModule, ok := styraclient.GetPolicyModule(res, "selector.rego")
 	if !ok {
-		return false, errors.New(errGetSelectorsInvalidResponse)
-	}
-
-	if _, exists := modules["selector.rego"]; !exists {
-		return false, errors.New(errGetSelectorsInvalidResponse)
-	}
-
-	selectorsModule, ok := modules["selector.rego"].(string)
-	if !ok {
-		return false, errors.New(errGetSelectorsInvalidResponse)
+		return false, nil
 	}
 
 	selectorsAreEqual, err := compareSelectors(selectorsModule, cr)
@@ -257,7 +242,7 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 
 	_, err := e.client.Stacks.DeleteStack(req)
 	if err != nil {
-		return errors.Wrap(err, errDeleteFailed)
+		return errors.Wrap(resource.Ignore(IsNotFound, err), errDeleteFailed)
 	}
 
 	return nil
@@ -479,6 +479,59 @@ func TestObserve(t *testing.T) {
 				err: errors.Wrap(errors.Wrap(errBoom, errGetSelectors), errIsUpToDateFailed),
 			},
 		},
+		"SelectorsPolicyNotFound": {
+			args: args{
+				styra: styra.StyraAPI{
+					Stacks: withMockStack(t, func(mcs *mockstack.MockClientService) {
+						mcs.EXPECT().
+							GetStack(&stacks.GetStackParams{
+								Stack:   testStackID,
+								Context: context.Background(),
+							}).
+							Return(&stacks.GetStackOK{
+								Payload: &models.StacksV1StacksGetResponse{
+									Result: &models.StacksV1StackConfig{
+										Description: &testDescription,
+										ReadOnly:    styraclient.Bool(true),
+										Type:        &testType,
+									},
+								},
+							}, nil)
+					}),
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							GetPolicy(&policies.GetPolicyParams{
+								Policy:  fmt.Sprintf("stacks/%s/selectors", testStackID),
+								Context: context.Background(),
+							}).
+							Return(nil, &policies.GetPolicyNotFound{})
+					}),
+				},
+				cr: Stack(
+					withExternalName(testStackID),
+					withSpec(v1alpha1.StackParameters{
+						Type:        testType,
+						Description: testDescription,
+						ReadOnly:    true,
+					}),
+				),
+			},
+			want: want{
+				cr: Stack(
+					withExternalName(testStackID),
+					withSpec(v1alpha1.StackParameters{
+						Type:        testType,
+						Description: testDescription,
+						ReadOnly:    true,
+					}),
+					withConditions(xpv1.Available()),
+				),
+				result: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -951,6 +1004,28 @@ func TestDelete(t *testing.T) {
 				err: errors.Wrap(errBoom, errDeleteFailed),
 			},
 		},
+		"NotFound": {
+			args: args{
+				styra: styra.StyraAPI{
+					Stacks: withMockStack(t, func(mcs *mockstack.MockClientService) {
+						mcs.EXPECT().
+							DeleteStack(&stacks.DeleteStackParams{
+								Stack:   testStackID,
+								Context: context.Background(),
+							}).
+							Return(nil, &styraclient.APIError{Operation: "DeleteStack", StatusCode: 404})
+					}),
+				},
+				cr: Stack(
+					withExternalName(testStackID),
+				),
+			},
+			want: want{
+				cr: Stack(
+					withExternalName(testStackID),
+				),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -70,8 +70,9 @@ func lateInitializeSourceControlConfig(spec, current *v1alpha1.V1SourceControlCo
 	return current
 }
 
-// IsNotFound returns whether the given error is of type NotFound or not.
+// IsNotFound returns whether the given error is of type NotFound or any
+// other Styra API error with status 404.
 func IsNotFound(err error) bool {
 	var snf *stacks.GetStackNotFound
-	return errors.As(err, &snf)
+	return errors.As(err, &snf) || styraclient.IsNotFound(err)
 }
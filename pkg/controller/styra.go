@@ -19,6 +19,7 @@ package controller
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
 	"github.com/crossplane-contrib/provider-styra/pkg/controller/config"
 	"github.com/crossplane-contrib/provider-styra/pkg/controller/datasource"
 	"github.com/crossplane-contrib/provider-styra/pkg/controller/secret"
@@ -30,8 +31,11 @@ import (
 // Setup creates all Cluster API controllers with the supplied logger and adds
 // them to the supplied manager.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
+	styraclient.SetLogger(o.Logger.WithValues("component", "styra-client"))
+
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
+		config.SetupHealth,
 		secret.SetupSecret,
 		system.SetupSystem,
 		stack.SetupStack,
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+)
+
+const (
+	// agentStaleAfter is the duration after which an agent that has not
+	// reported to Styra is considered stale.
+	agentStaleAfter = 5 * time.Minute
+
+	// defaultAgentsInterval is the minimum interval between two
+	// observations of the agents of a system if none is configured.
+	defaultAgentsInterval = time.Minute
+
+	// maxAgentItems is the maximum number of agents that are listed in the
+	// status of a system. Systems with sidecars can have thousands of them.
+	maxAgentItems = 10
+
+	errGetAgents = "cannot get system agents"
+)
+
+// agentStatus contains the observed fields of the agent status. The Styra
+// API does not specify its schema.
+type agentStatus struct {
+	Version    string `json:"version"`
+	OPAVersion string `json:"opa_version"`
+	LastSeen   string `json:"last_seen"`
+	Status     string `json:"status"`
+}
+
+// observeAgents records the agents of cr in its status and sets the
+// AgentsHealthy condition if they are due to be observed. The last
+// observation is kept otherwise. Agents are not observed for system types
+// without agents. Failing to get the agents does not fail the observation of
+// the system itself.
+func (e *external) observeAgents(ctx context.Context, cr *v1alpha1.System, last *v1alpha1.SystemAgentsObservation) {
+	if info, _ := v1alpha1.LookupSystemType(cr.Spec.ForProvider.Type); !info.Agents {
+		return
+	}
+
+	now := time.Now()
+	if !isAgentsObservationDue(cr.Spec.ForProvider.AgentsInterval, last, now) {
+		cr.Status.AtProvider.Agents = last
+		return
+	}
+
+	resp, err := e.client.Systems.GetSystemAgents(&systems.GetSystemAgentsParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+	})
+	if err != nil {
+		cr.Status.AtProvider.Agents = last
+		cr.SetConditions(v1alpha1.AgentsUnknown(errors.Wrap(err, errGetAgents)))
+		return
+	}
+
+	obs := generateAgentsObservation(resp.Payload, now)
+	obs.LastObservedAt = &metav1.Time{Time: now}
+	cr.Status.AtProvider.Agents = obs
+	cr.SetConditions(agentsCondition(obs))
+}
+
+// isAgentsObservationDue returns whether the agents need to be observed at
+// time now given the last observation.
+func isAgentsObservationDue(interval *metav1.Duration, last *v1alpha1.SystemAgentsObservation, now time.Time) bool {
+	if last == nil || last.LastObservedAt == nil {
+		return true
+	}
+	d := defaultAgentsInterval
+	if interval != nil {
+		d = interval.Duration
+	}
+	return !now.Before(last.LastObservedAt.Add(d))
+}
+
+// generateAgentsObservation generates the observation of the given agents at
+// time now.
+func generateAgentsObservation(resp *models.SystemsV1SystemsGetAgentsResponse, now time.Time) *v1alpha1.SystemAgentsObservation {
+	obs := &v1alpha1.SystemAgentsObservation{}
+	if resp == nil {
+		return obs
+	}
+	for _, a := range resp.Result {
+		if a == nil {
+			continue
+		}
+		agent := generateAgent(a, now)
+		if agent.Stale {
+			obs.Stale++
+		} else {
+			obs.Connected++
+		}
+		obs.Items = append(obs.Items, agent)
+	}
+
+	// List the most recently seen agents only. Agents that have never been
+	// seen come last.
+	sort.SliceStable(obs.Items, func(i, j int) bool {
+		a, b := obs.Items[i].LastSeen, obs.Items[j].LastSeen
+		return a != nil && (b == nil || a.After(b.Time))
+	})
+	if len(obs.Items) > maxAgentItems {
+		obs.Items = obs.Items[:maxAgentItems]
+	}
+	return obs
+}
+
+func generateAgent(a *models.SystemsV1AgentConfig, now time.Time) v1alpha1.SystemAgent {
+	agent := v1alpha1.SystemAgent{
+		ID:    styraclient.StringValue(a.ID),
+		Stale: true,
+	}
+
+	status := agentStatus{}
+	raw, err := json.Marshal(a.Status)
+	if err != nil || json.Unmarshal(raw, &status) != nil {
+		return agent
+	}
+
+	agent.Version = status.Version
+	if agent.Version == "" {
+		agent.Version = status.OPAVersion
+	}
+	if lastSeen, err := time.Parse(time.RFC3339, status.LastSeen); err == nil {
+		agent.LastSeen = &metav1.Time{Time: lastSeen}
+		agent.Stale = now.Sub(lastSeen) > agentStaleAfter
+		return agent
+	}
+	// Fall back to the reported status if the agent has never been seen.
+	agent.Stale = status.Status != "ok" && status.Status != "online"
+	return agent
+}
+
+// agentsCondition returns the AgentsHealthy condition for the given agents.
+// Agents are healthy if at least one of them is connected.
+func agentsCondition(obs *v1alpha1.SystemAgentsObservation) xpv1.Condition {
+	switch {
+	case obs.Connected > 0:
+		return v1alpha1.AgentsConnected(obs.Connected, obs.Stale)
+	case obs.Stale > 0:
+		return v1alpha1.AgentsStale(obs.Stale)
+	default:
+		return v1alpha1.NoAgents()
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	mocksystem "github.com/crossplane-contrib/provider-styra/pkg/client/mock/systems"
+)
+
+func TestGenerateAgentsObservation(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Minute)
+	old := now.Add(-time.Hour)
+	agentA, agentB := "agent-a", "agent-b"
+
+	many := &models.SystemsV1SystemsGetAgentsResponse{}
+	manyItems := []v1alpha1.SystemAgent{}
+	for i := 0; i < maxAgentItems+2; i++ {
+		id := fmt.Sprintf("agent-%d", i)
+		lastSeen := now.Add(-time.Duration(maxAgentItems+2-i) * time.Second)
+		many.Result = append(many.Result, &models.SystemsV1AgentConfig{ID: &id, Status: map[string]interface{}{"last_seen": lastSeen.Format(time.RFC3339)}})
+		manyItems = append([]v1alpha1.SystemAgent{{ID: id, LastSeen: &metav1.Time{Time: lastSeen}}}, manyItems...)
+	}
+
+	type want struct {
+		obs       *v1alpha1.SystemAgentsObservation
+		condition xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		resp *models.SystemsV1SystemsGetAgentsResponse
+		want
+	}{
+		"NoAgents": {
+			resp: &models.SystemsV1SystemsGetAgentsResponse{},
+			want: want{
+				obs:       &v1alpha1.SystemAgentsObservation{},
+				condition: v1alpha1.NoAgents(),
+			},
+		},
+		"ConnectedAndStale": {
+			resp: &models.SystemsV1SystemsGetAgentsResponse{
+				Result: []*models.SystemsV1AgentConfig{
+					{ID: &agentA, Status: map[string]interface{}{"version": "0.40.0", "last_seen": recent.Format(time.RFC3339)}},
+					{ID: &agentB, Status: map[string]interface{}{"opa_version": "0.39.0", "last_seen": old.Format(time.RFC3339)}},
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemAgentsObservation{
+					Connected: 1,
+					Stale:     1,
+					Items: []v1alpha1.SystemAgent{
+						{ID: agentA, Version: "0.40.0", LastSeen: &metav1.Time{Time: recent}},
+						{ID: agentB, Version: "0.39.0", LastSeen: &metav1.Time{Time: old}, Stale: true},
+					},
+				},
+				condition: v1alpha1.AgentsConnected(1, 1),
+			},
+		},
+		"OnlyStale": {
+			resp: &models.SystemsV1SystemsGetAgentsResponse{
+				Result: []*models.SystemsV1AgentConfig{
+					{ID: &agentA, Status: map[string]interface{}{"status": "offline"}},
+					{ID: &agentB},
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemAgentsObservation{
+					Stale: 2,
+					Items: []v1alpha1.SystemAgent{
+						{ID: agentA, Stale: true},
+						{ID: agentB, Stale: true},
+					},
+				},
+				condition: v1alpha1.AgentsStale(2),
+			},
+		},
+		"MostRecentlySeen": {
+			resp: &models.SystemsV1SystemsGetAgentsResponse{
+				Result: []*models.SystemsV1AgentConfig{
+					{ID: &agentA, Status: map[string]interface{}{"status": "ok"}},
+					{ID: &agentB, Status: map[string]interface{}{"last_seen": old.Format(time.RFC3339)}},
+					many.Result[0],
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemAgentsObservation{
+					Connected: 2,
+					Stale:     1,
+					Items: []v1alpha1.SystemAgent{
+						manyItems[maxAgentItems+1],
+						{ID: agentB, LastSeen: &metav1.Time{Time: old}, Stale: true},
+						{ID: agentA},
+					},
+				},
+				condition: v1alpha1.AgentsConnected(2, 1),
+			},
+		},
+		"TooManyAgents": {
+			resp: many,
+			want: want{
+				obs: &v1alpha1.SystemAgentsObservation{
+					Connected: maxAgentItems + 2,
+					Items:     manyItems[:maxAgentItems],
+				},
+				condition: v1alpha1.AgentsConnected(maxAgentItems+2, 0),
+			},
+		},
+		"StatusWithoutLastSeen": {
+			resp: &models.SystemsV1SystemsGetAgentsResponse{
+				Result: []*models.SystemsV1AgentConfig{
+					{ID: &agentA, Status: map[string]interface{}{"status": "ok"}},
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemAgentsObservation{
+					Connected: 1,
+					Items: []v1alpha1.SystemAgent{
+						{ID: agentA},
+					},
+				},
+				condition: v1alpha1.AgentsConnected(1, 0),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			obs := generateAgentsObservation(tc.resp, now)
+			if diff := cmp.Diff(tc.want.obs, obs); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.condition, agentsCondition(obs), test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsAgentsObservationDue(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	observedAt := func(d time.Duration) *v1alpha1.SystemAgentsObservation {
+		return &v1alpha1.SystemAgentsObservation{
+			LastObservedAt: &metav1.Time{Time: now.Add(-d)},
+		}
+	}
+
+	cases := map[string]struct {
+		interval *metav1.Duration
+		last     *v1alpha1.SystemAgentsObservation
+		want     bool
+	}{
+		"NeverObserved": {
+			want: true,
+		},
+		"NoObservationTime": {
+			last: &v1alpha1.SystemAgentsObservation{Connected: 1},
+			want: true,
+		},
+		"WithinDefaultInterval": {
+			last: observedAt(30 * time.Second),
+			want: false,
+		},
+		"DefaultIntervalElapsed": {
+			last: observedAt(defaultAgentsInterval),
+			want: true,
+		},
+		"WithinCustomInterval": {
+			interval: &metav1.Duration{Duration: 5 * time.Minute},
+			last:     observedAt(2 * time.Minute),
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := isAgentsObservationDue(tc.interval, tc.last, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObserveAgents(t *testing.T) {
+	recent := &v1alpha1.SystemAgentsObservation{
+		Connected:      1,
+		LastObservedAt: &metav1.Time{Time: time.Now()},
+	}
+	outdated := &v1alpha1.SystemAgentsObservation{
+		Connected:      1,
+		LastObservedAt: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+	}
+
+	type want struct {
+		obs        *v1alpha1.SystemAgentsObservation
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		systemType string
+		last       *v1alpha1.SystemAgentsObservation
+		systems    *mocksystem.MockClientService
+		want       want
+	}{
+		"TypeWithoutAgents": {
+			systemType: v1alpha1.SystemTypeTerraform,
+			systems:    withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want:       want{},
+		},
+		"NotDue": {
+			systemType: testType,
+			last:       recent,
+			systems:    withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want:       want{obs: recent},
+		},
+		"Due": {
+			systemType: testType,
+			last:       outdated,
+			systems:    withMockSystem(t, expectNoAgents),
+			want: want{
+				obs:        &v1alpha1.SystemAgentsObservation{},
+				conditions: []xpv1.Condition{v1alpha1.NoAgents()},
+			},
+		},
+		"GetAgentsFailed": {
+			systemType: testType,
+			last:       outdated,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					GetSystemAgents(gomock.Any()).
+					Return(nil, errBoom)
+			}),
+			want: want{
+				obs:        outdated,
+				conditions: []xpv1.Condition{v1alpha1.AgentsUnknown(errors.Wrap(errBoom, errGetAgents))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(
+				withExternalName(testSystemID),
+				withSpec(v1alpha1.SystemParameters{Type: tc.systemType}),
+			)
+			e := &external{client: &styra.StyraAPI{Systems: tc.systems}, recorder: event.NewNopRecorder()}
+			e.observeAgents(context.Background(), cr, tc.last)
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider.Agents, cmpopts.IgnoreFields(v1alpha1.SystemAgentsObservation{}, "LastObservedAt")); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, cr.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+)
+
+const (
+	// defaultBundleInterval is the minimum interval between two
+	// observations of the bundle of a system if none is configured.
+	defaultBundleInterval = time.Minute
+
+	errGetBundles      = "cannot get system bundles"
+	errGetBundleDeploy = "cannot get system bundle deploy status"
+	errDeployBundle    = "cannot deploy bundle"
+	errPinnedBundleID  = "bundleId must be set if the bundle deployment policy is Pinned"
+	errBundleNotFound  = "cannot find bundle %s"
+)
+
+// observeBundle records the latest policy bundle of cr in its status and sets
+// the BundleCompiled condition if it is due to be observed. The last
+// observation is kept otherwise. It returns whether the deployed bundle
+// matches the bundle deployment of cr. Failing to get the bundle does not
+// fail the observation of the system itself unless the bundle deployment is
+// managed.
+func (e *external) observeBundle(ctx context.Context, cr *v1alpha1.System, last *v1alpha1.SystemBundleObservation) (bool, error) {
+	spec := cr.Spec.ForProvider.BundleDeployment
+	now := time.Now()
+	if spec == nil && !isBundleObservationDue(cr.Spec.ForProvider.BundleInterval, last, now) {
+		cr.Status.AtProvider.Bundle = last
+		return true, nil
+	}
+
+	bundles, deploy, err := e.getBundles(ctx, cr)
+	if err != nil {
+		cr.Status.AtProvider.Bundle = last
+		cr.SetConditions(v1alpha1.BundleUnknown(err))
+		if spec != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	obs := generateBundleObservation(bundles, deploy)
+	obs.LastObservedAt = &metav1.Time{Time: now}
+	cr.Status.AtProvider.Bundle = obs
+	cr.SetConditions(bundleCondition(obs))
+	if spec == nil {
+		return true, nil
+	}
+	return observeBundleDeployment(cr, bundles, deploy), nil
+}
+
+// isBundleObservationDue returns whether the bundle needs to be observed at
+// time now given the last observation.
+func isBundleObservationDue(interval *metav1.Duration, last *v1alpha1.SystemBundleObservation, now time.Time) bool {
+	if last == nil || last.LastObservedAt == nil {
+		return true
+	}
+	d := defaultBundleInterval
+	if interval != nil {
+		d = interval.Duration
+	}
+	return !now.Before(last.LastObservedAt.Add(d))
+}
+
+// observeBundleDeployment sets the BundleDeployed condition of cr and returns
+// whether the deployed bundle is the one selected by the bundle deployment of
+// cr.
+func observeBundleDeployment(cr *v1alpha1.System, bundles *models.SystemsV1SystemsGetBundlesResponse, deploy *models.SystemsV1SystemsGetBundleDeployResponse) bool {
+	bundle, err := desiredBundle(cr.Spec.ForProvider.BundleDeployment, bundles)
+	if err != nil {
+		// Deploying again does not resolve an invalid bundle deployment.
+		// Report it as up to date so that the system is not updated on
+		// every poll until the bundle deployment is changed.
+		cr.SetConditions(v1alpha1.InvalidBundleDeployment(err))
+		return true
+	}
+	if bundle == nil {
+		// Nothing to deploy until a bundle has been compiled.
+		cr.SetConditions(v1alpha1.NoBundleToDeploy())
+		return true
+	}
+	id := styraclient.StringValue(bundle.ID)
+	if !isBundleDeployed(bundle, deploy) {
+		cr.SetConditions(v1alpha1.BundleDeployPending(id))
+		return false
+	}
+	cr.SetConditions(v1alpha1.BundleDeployed(id))
+	return true
+}
+
+// getBundles gets the bundles and the deploy status of cr.
+func (e *external) getBundles(ctx context.Context, cr *v1alpha1.System) (*models.SystemsV1SystemsGetBundlesResponse, *models.SystemsV1SystemsGetBundleDeployResponse, error) {
+	// The details of a bundle equal its entry in the list of bundles, so
+	// they are not requested separately. Compile errors and the activation
+	// are only part of the deploy status.
+	bundles, err := e.client.Systems.GetSystemBundles(&systems.GetSystemBundlesParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGetBundles)
+	}
+	deploy, err := e.client.Systems.GetSystemBundleDeploy(&systems.GetSystemBundleDeployParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGetBundleDeploy)
+	}
+	return bundles.Payload, deploy.Payload, nil
+}
+
+// deployBundle activates the bundle selected by the bundle deployment of cr
+// unless it is deployed already.
+func (e *external) deployBundle(ctx context.Context, cr *v1alpha1.System) error {
+	spec := cr.Spec.ForProvider.BundleDeployment
+	if spec == nil {
+		return nil
+	}
+
+	bundles, deploy, err := e.getBundles(ctx, cr)
+	if err != nil {
+		return err
+	}
+	bundle, err := desiredBundle(spec, bundles)
+	if err != nil || bundle == nil {
+		// The BundleDeployed condition already reports invalid bundle
+		// deployments and systems without a bundle. Do not fail updates
+		// of the rest of the system because of them.
+		return nil
+	}
+	if isBundleDeployed(bundle, deploy) {
+		// Deploying again would only force the agents to reload it.
+		return nil
+	}
+
+	_, err = e.client.Systems.UpdateSystemBundleDeploy(&systems.UpdateSystemBundleDeployParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+		Body: &models.SystemsV1SystemsPutBundleDeployRequest{
+			Force: styraclient.Bool(styraclient.BoolValue(spec.Force)),
+			Primary: &models.SystemsV1BundleActivation{
+				ID:       bundle.ID,
+				Revision: bundle.Revision,
+				Version:  bundle.Version,
+			},
+		},
+	})
+	return errors.Wrap(err, errDeployBundle)
+}
+
+// desiredBundle returns the bundle selected by the given bundle deployment. It
+// returns nil if no bundle has been compiled yet.
+func desiredBundle(spec *v1alpha1.SystemBundleDeployment, bundles *models.SystemsV1SystemsGetBundlesResponse) (*models.SystemsV1Bundle, error) {
+	if spec.Policy != v1alpha1.BundleDeploymentPolicyPinned {
+		return latestBundle(bundles), nil
+	}
+
+	id := styraclient.StringValue(spec.BundleID)
+	if id == "" {
+		return nil, errors.New(errPinnedBundleID)
+	}
+	if bundles != nil {
+		for _, b := range bundles.Result {
+			if b != nil && styraclient.StringValue(b.ID) == id {
+				return b, nil
+			}
+		}
+	}
+	return nil, errors.Errorf(errBundleNotFound, id)
+}
+
+// isBundleDeployed returns whether bundle is the primary bundle of the
+// given deploy status.
+func isBundleDeployed(bundle *models.SystemsV1Bundle, deploy *models.SystemsV1SystemsGetBundleDeployResponse) bool {
+	if deploy == nil || deploy.Result == nil || deploy.Result.Primary == nil {
+		return false
+	}
+	return styraclient.StringValue(deploy.Result.Primary.ID) == styraclient.StringValue(bundle.ID)
+}
+
+// generateBundleObservation generates the observation of the latest bundle
+// from the list of bundles and the deploy status of a system.
+func generateBundleObservation(bundles *models.SystemsV1SystemsGetBundlesResponse, deploy *models.SystemsV1SystemsGetBundleDeployResponse) *v1alpha1.SystemBundleObservation {
+	obs := &v1alpha1.SystemBundleObservation{}
+	if deploy != nil && deploy.Result != nil && len(deploy.Result.BuildErrors) > 0 {
+		obs.CompileErrors = deploy.Result.BuildErrors
+	}
+
+	latest := latestBundle(bundles)
+	if latest == nil {
+		return obs
+	}
+	obs.ID = styraclient.StringValue(latest.ID)
+	obs.Version = styraclient.Int64Value(latest.Version)
+	obs.Revision = styraclient.StringValue(latest.Revision)
+	if latest.CreatedAt != nil {
+		obs.CompiledAt = &metav1.Time{Time: time.Time(*latest.CreatedAt)}
+	}
+	if t := time.Time(latest.LastDeployedAt); !t.IsZero() {
+		obs.LastDeployedAt = &metav1.Time{Time: t}
+	}
+
+	obs.DeployState = v1alpha1.BundleDeployStateInactive
+	var primary *models.SystemsV1BundleActivation
+	if deploy != nil && deploy.Result != nil {
+		primary = deploy.Result.Primary
+	}
+	if primary != nil && styraclient.StringValue(primary.ID) == obs.ID {
+		switch active := styraclient.Int64Value(latest.Active); {
+		case active >= 100:
+			obs.DeployState = v1alpha1.BundleDeployStateActive
+		case active > 0:
+			obs.DeployState = v1alpha1.BundleDeployStateActivating
+		}
+	}
+	return obs
+}
+
+// latestBundle returns the bundle with the highest version.
+func latestBundle(bundles *models.SystemsV1SystemsGetBundlesResponse) *models.SystemsV1Bundle {
+	if bundles == nil {
+		return nil
+	}
+	var latest *models.SystemsV1Bundle
+	for _, b := range bundles.Result {
+		if b == nil {
+			continue
+		}
+		if latest == nil || styraclient.Int64Value(b.Version) > styraclient.Int64Value(latest.Version) {
+			latest = b
+		}
+	}
+	return latest
+}
+
+// bundleCondition returns the BundleCompiled condition for the given bundle.
+func bundleCondition(obs *v1alpha1.SystemBundleObservation) xpv1.Condition {
+	if len(obs.CompileErrors) > 0 {
+		ids := make([]string, 0, len(obs.CompileErrors))
+		for id := range obs.CompileErrors {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		msgs := make([]string, len(ids))
+		for i, id := range ids {
+			msgs[i] = fmt.Sprintf("%s: %s", id, obs.CompileErrors[id])
+		}
+		return v1alpha1.BundleCompileFailed(strings.Join(msgs, "; "))
+	}
+	if obs.ID == "" {
+		return v1alpha1.NoBundle()
+	}
+	return v1alpha1.BundleCompiled(obs.ID)
+}
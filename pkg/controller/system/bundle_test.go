@@ -0,0 +1,506 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+	mocksystem "github.com/crossplane-contrib/provider-styra/pkg/client/mock/systems"
+)
+
+func TestGenerateBundleObservation(t *testing.T) {
+	compiled := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	compiledAt := strfmt.DateTime(compiled)
+	oldID, latestID := "bundle-1", "bundle-2"
+	revision := "d5e9f2"
+
+	bundles := &models.SystemsV1SystemsGetBundlesResponse{
+		Result: []*models.SystemsV1Bundle{
+			{ID: &latestID, Version: styraclient.Int64(2), Revision: &revision, CreatedAt: &compiledAt, Active: styraclient.Int64(50)},
+			{ID: &oldID, Version: styraclient.Int64(1), Active: styraclient.Int64(50)},
+		},
+	}
+
+	type want struct {
+		obs       *v1alpha1.SystemBundleObservation
+		condition xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		bundles *models.SystemsV1SystemsGetBundlesResponse
+		deploy  *models.SystemsV1SystemsGetBundleDeployResponse
+		want
+	}{
+		"NoBundle": {
+			bundles: &models.SystemsV1SystemsGetBundlesResponse{},
+			deploy:  &models.SystemsV1SystemsGetBundleDeployResponse{},
+			want: want{
+				obs:       &v1alpha1.SystemBundleObservation{},
+				condition: v1alpha1.NoBundle(),
+			},
+		},
+		"LatestActivating": {
+			bundles: bundles,
+			deploy: &models.SystemsV1SystemsGetBundleDeployResponse{
+				Result: &models.SystemsV1BundleDeployStatus{
+					Primary: &models.SystemsV1BundleActivation{ID: &latestID},
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemBundleObservation{
+					ID:          latestID,
+					Version:     2,
+					Revision:    revision,
+					CompiledAt:  &metav1.Time{Time: compiled},
+					DeployState: v1alpha1.BundleDeployStateActivating,
+				},
+				condition: v1alpha1.BundleCompiled(latestID),
+			},
+		},
+		"LatestInactive": {
+			bundles: bundles,
+			deploy: &models.SystemsV1SystemsGetBundleDeployResponse{
+				Result: &models.SystemsV1BundleDeployStatus{
+					Primary: &models.SystemsV1BundleActivation{ID: &oldID},
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemBundleObservation{
+					ID:          latestID,
+					Version:     2,
+					Revision:    revision,
+					CompiledAt:  &metav1.Time{Time: compiled},
+					DeployState: v1alpha1.BundleDeployStateInactive,
+				},
+				condition: v1alpha1.BundleCompiled(latestID),
+			},
+		},
+		"CompileFailed": {
+			bundles: &models.SystemsV1SystemsGetBundlesResponse{},
+			deploy: &models.SystemsV1SystemsGetBundleDeployResponse{
+				Result: &models.SystemsV1BundleDeployStatus{
+					BuildErrors: map[string]string{
+						"b": "rego_parse_error",
+						"a": "rego_type_error",
+					},
+				},
+			},
+			want: want{
+				obs: &v1alpha1.SystemBundleObservation{
+					CompileErrors: map[string]string{
+						"b": "rego_parse_error",
+						"a": "rego_type_error",
+					},
+				},
+				condition: v1alpha1.BundleCompileFailed("a: rego_type_error; b: rego_parse_error"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			obs := generateBundleObservation(tc.bundles, tc.deploy)
+			if diff := cmp.Diff(tc.want.obs, obs); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.condition, bundleCondition(obs), test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObserveBundleDeployment(t *testing.T) {
+	oldID, latestID := "bundle-1", "bundle-2"
+	bundles := &models.SystemsV1SystemsGetBundlesResponse{
+		Result: []*models.SystemsV1Bundle{
+			{ID: &oldID, Version: styraclient.Int64(1)},
+			{ID: &latestID, Version: styraclient.Int64(2)},
+		},
+	}
+	deployed := func(id string) *models.SystemsV1SystemsGetBundleDeployResponse {
+		return &models.SystemsV1SystemsGetBundleDeployResponse{
+			Result: &models.SystemsV1BundleDeployStatus{
+				Primary: &models.SystemsV1BundleActivation{ID: &id},
+			},
+		}
+	}
+
+	type want struct {
+		upToDate  bool
+		condition xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		spec    *v1alpha1.SystemBundleDeployment
+		bundles *models.SystemsV1SystemsGetBundlesResponse
+		deploy  *models.SystemsV1SystemsGetBundleDeployResponse
+		want    want
+	}{
+		"LatestDeployed": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyLatest},
+			bundles: bundles,
+			deploy:  deployed(latestID),
+			want:    want{upToDate: true, condition: v1alpha1.BundleDeployed(latestID)},
+		},
+		"LatestNotDeployed": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyLatest},
+			bundles: bundles,
+			deploy:  deployed(oldID),
+			want:    want{upToDate: false, condition: v1alpha1.BundleDeployPending(latestID)},
+		},
+		"LatestWithoutBundles": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyLatest},
+			bundles: &models.SystemsV1SystemsGetBundlesResponse{},
+			deploy:  &models.SystemsV1SystemsGetBundleDeployResponse{},
+			want:    want{upToDate: true, condition: v1alpha1.NoBundleToDeploy()},
+		},
+		"PinnedDeployed": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyPinned, BundleID: &oldID},
+			bundles: bundles,
+			deploy:  deployed(oldID),
+			want:    want{upToDate: true, condition: v1alpha1.BundleDeployed(oldID)},
+		},
+		"PinnedDrifted": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyPinned, BundleID: &oldID},
+			bundles: bundles,
+			deploy:  deployed(latestID),
+			want:    want{upToDate: false, condition: v1alpha1.BundleDeployPending(oldID)},
+		},
+		// A missing pinned bundle must not trigger an update on every poll.
+		"PinnedNotFound": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyPinned, BundleID: styraclient.String("unknown")},
+			bundles: bundles,
+			deploy:  deployed(oldID),
+			want: want{
+				upToDate:  true,
+				condition: v1alpha1.InvalidBundleDeployment(errors.Errorf(errBundleNotFound, "unknown")),
+			},
+		},
+		"PinnedWithoutBundleID": {
+			spec:    &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyPinned},
+			bundles: bundles,
+			deploy:  deployed(oldID),
+			want: want{
+				upToDate:  true,
+				condition: v1alpha1.InvalidBundleDeployment(errors.New(errPinnedBundleID)),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(withSpec(v1alpha1.SystemParameters{
+				CustomSystemParameters: v1alpha1.CustomSystemParameters{
+					BundleDeployment: tc.spec,
+				},
+			}))
+			upToDate := observeBundleDeployment(cr, tc.bundles, tc.deploy)
+			if diff := cmp.Diff(tc.want.upToDate, upToDate); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.condition, cr.GetCondition(v1alpha1.TypeBundleDeployed), test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeployBundle(t *testing.T) {
+	bundleID := "bundle-1"
+	revision := "d5e9f2"
+
+	expectBundlesDeployed := func(primary *models.SystemsV1BundleActivation) mockSystemModifier {
+		return func(mcs *mocksystem.MockClientService) {
+			mcs.EXPECT().
+				GetSystemBundles(&systems.GetSystemBundlesParams{
+					System:  testSystemID,
+					Context: context.Background(),
+				}).
+				Return(&systems.GetSystemBundlesOK{
+					Payload: &models.SystemsV1SystemsGetBundlesResponse{
+						Result: []*models.SystemsV1Bundle{
+							{ID: &bundleID, Revision: &revision, Version: styraclient.Int64(1)},
+						},
+					},
+				}, nil)
+			mcs.EXPECT().
+				GetSystemBundleDeploy(&systems.GetSystemBundleDeployParams{
+					System:  testSystemID,
+					Context: context.Background(),
+				}).
+				Return(&systems.GetSystemBundleDeployOK{
+					Payload: &models.SystemsV1SystemsGetBundleDeployResponse{
+						Result: &models.SystemsV1BundleDeployStatus{Primary: primary},
+					},
+				}, nil)
+		}
+	}
+	expectBundles := expectBundlesDeployed(nil)
+
+	cases := map[string]struct {
+		spec    *v1alpha1.SystemBundleDeployment
+		systems *mocksystem.MockClientService
+		want    error
+	}{
+		"NotManaged": {
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+		},
+		"DeployPinned": {
+			spec: &v1alpha1.SystemBundleDeployment{
+				Policy:   v1alpha1.BundleDeploymentPolicyPinned,
+				BundleID: &bundleID,
+				Force:    styraclient.Bool(true),
+			},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				expectBundles(mcs)
+				mcs.EXPECT().
+					UpdateSystemBundleDeploy(&systems.UpdateSystemBundleDeployParams{
+						System:  testSystemID,
+						Context: context.Background(),
+						Body: &models.SystemsV1SystemsPutBundleDeployRequest{
+							Force: styraclient.Bool(true),
+							Primary: &models.SystemsV1BundleActivation{
+								ID:       &bundleID,
+								Revision: &revision,
+								Version:  styraclient.Int64(1),
+							},
+						},
+					}).
+					Return(&systems.UpdateSystemBundleDeployOK{}, nil)
+			}),
+		},
+		"PinnedWithoutBundleID": {
+			spec: &v1alpha1.SystemBundleDeployment{
+				Policy: v1alpha1.BundleDeploymentPolicyPinned,
+			},
+			systems: withMockSystem(t, expectBundles),
+		},
+		"PinnedBundleNotFound": {
+			spec: &v1alpha1.SystemBundleDeployment{
+				Policy:   v1alpha1.BundleDeploymentPolicyPinned,
+				BundleID: styraclient.String("bundle-0"),
+			},
+			systems: withMockSystem(t, expectBundles),
+		},
+		"NoBundle": {
+			spec: &v1alpha1.SystemBundleDeployment{
+				Policy: v1alpha1.BundleDeploymentPolicyLatest,
+			},
+			systems: withMockSystem(t, expectNoBundle),
+		},
+		"AlreadyDeployed": {
+			spec: &v1alpha1.SystemBundleDeployment{
+				Policy: v1alpha1.BundleDeploymentPolicyLatest,
+				Force:  styraclient.Bool(true),
+			},
+			systems: withMockSystem(t, expectBundlesDeployed(&models.SystemsV1BundleActivation{ID: &bundleID})),
+		},
+		"DeployFailed": {
+			spec: &v1alpha1.SystemBundleDeployment{
+				Policy: v1alpha1.BundleDeploymentPolicyLatest,
+			},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				expectBundles(mcs)
+				mcs.EXPECT().
+					UpdateSystemBundleDeploy(gomock.Any()).
+					Return(nil, errBoom)
+			}),
+			want: errors.Wrap(errBoom, errDeployBundle),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(
+				withExternalName(testSystemID),
+				withSpec(v1alpha1.SystemParameters{
+					CustomSystemParameters: v1alpha1.CustomSystemParameters{
+						BundleDeployment: tc.spec,
+					},
+				}),
+			)
+			e := &external{client: &styra.StyraAPI{Systems: tc.systems}, recorder: event.NewNopRecorder()}
+			err := e.deployBundle(context.Background(), cr)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsBundleObservationDue(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	observedAt := func(d time.Duration) *v1alpha1.SystemBundleObservation {
+		return &v1alpha1.SystemBundleObservation{
+			LastObservedAt: &metav1.Time{Time: now.Add(-d)},
+		}
+	}
+
+	cases := map[string]struct {
+		interval *metav1.Duration
+		last     *v1alpha1.SystemBundleObservation
+		want     bool
+	}{
+		"NeverObserved": {
+			want: true,
+		},
+		"NoObservationTime": {
+			last: &v1alpha1.SystemBundleObservation{ID: "b1"},
+			want: true,
+		},
+		"WithinDefaultInterval": {
+			last: observedAt(30 * time.Second),
+			want: false,
+		},
+		"DefaultIntervalElapsed": {
+			last: observedAt(defaultBundleInterval),
+			want: true,
+		},
+		"WithinCustomInterval": {
+			interval: &metav1.Duration{Duration: 5 * time.Minute},
+			last:     observedAt(2 * time.Minute),
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := isBundleObservationDue(tc.interval, tc.last, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObserveBundle(t *testing.T) {
+	latest := &v1alpha1.SystemBundleDeployment{Policy: v1alpha1.BundleDeploymentPolicyLatest}
+	recent := &v1alpha1.SystemBundleObservation{ID: "b1", LastObservedAt: &metav1.Time{Time: time.Now()}}
+
+	type want struct {
+		upToDate   bool
+		err        error
+		obs        *v1alpha1.SystemBundleObservation
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		spec    *v1alpha1.SystemBundleDeployment
+		last    *v1alpha1.SystemBundleObservation
+		systems *mocksystem.MockClientService
+		want    want
+	}{
+		"NotManaged": {
+			systems: withMockSystem(t, expectNoBundle),
+			want: want{
+				upToDate:   true,
+				obs:        &v1alpha1.SystemBundleObservation{},
+				conditions: []xpv1.Condition{v1alpha1.NoBundle()},
+			},
+		},
+		"NotDue": {
+			last:    recent,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want: want{
+				upToDate: true,
+				obs:      recent,
+			},
+		},
+		"NoBundle": {
+			spec:    latest,
+			last:    recent,
+			systems: withMockSystem(t, expectNoBundle),
+			want: want{
+				upToDate:   true,
+				obs:        &v1alpha1.SystemBundleObservation{},
+				conditions: []xpv1.Condition{v1alpha1.NoBundle(), v1alpha1.NoBundleToDeploy()},
+			},
+		},
+		"GetBundlesFailedNotManaged": {
+			last: &v1alpha1.SystemBundleObservation{ID: "b1"},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					GetSystemBundles(gomock.Any()).
+					Return(nil, errBoom)
+			}),
+			want: want{
+				upToDate:   true,
+				obs:        &v1alpha1.SystemBundleObservation{ID: "b1"},
+				conditions: []xpv1.Condition{v1alpha1.BundleUnknown(errors.Wrap(errBoom, errGetBundles))},
+			},
+		},
+		"GetBundlesFailed": {
+			spec: latest,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					GetSystemBundles(gomock.Any()).
+					Return(nil, errBoom)
+			}),
+			want: want{
+				err:        errors.Wrap(errBoom, errGetBundles),
+				conditions: []xpv1.Condition{v1alpha1.BundleUnknown(errors.Wrap(errBoom, errGetBundles))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(
+				withExternalName(testSystemID),
+				withSpec(v1alpha1.SystemParameters{
+					CustomSystemParameters: v1alpha1.CustomSystemParameters{
+						BundleDeployment: tc.spec,
+					},
+				}),
+			)
+			e := &external{client: &styra.StyraAPI{Systems: tc.systems}, recorder: event.NewNopRecorder()}
+			upToDate, err := e.observeBundle(context.Background(), cr, tc.last)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, upToDate); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider.Bundle, cmpopts.IgnoreFields(v1alpha1.SystemBundleObservation{}, "LastObservedAt")); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, cr.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
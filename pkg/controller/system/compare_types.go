@@ -1,6 +1,10 @@
 package system
 
 import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
 	models "github.com/mistermx/styra-go-client/pkg/models"
 
 	v1alpha1 "github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
@@ -24,5 +28,30 @@ func isEqualSystemDeploymentParameters(spec *v1alpha1.V1SystemDeploymentParamete
 		styraclient.StringValue(spec.NoProxy) == current.NoProxy &&
 		styraclient.Int32Value(spec.TimeoutSeconds) == current.TimeoutSeconds &&
 		styraclient.IsEqualStringArrayContent(spec.TrustedCaCerts, current.TrustedCaCerts) &&
-		styraclient.StringValue(spec.TrustedContainerRegistry) == current.TrustedContainerRegistry
+		styraclient.StringValue(spec.TrustedContainerRegistry) == current.TrustedContainerRegistry &&
+		isEqualExtra(spec.Extra, current.Extra)
+}
+
+func isEqualExtra(spec *runtime.RawExtension, current interface{}) bool {
+	if spec == nil {
+		return current == nil
+	}
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return false
+	}
+	return styraclient.IsEqualJSON(spec.Raw, raw)
+}
+
+func isEqualSourceControlConfig(spec *v1alpha1.V1SourceControlConfig, current *models.GitV1SourceControlConfig) bool {
+	if spec == nil {
+		return current == nil
+	}
+	if current == nil || current.Origin == nil {
+		return false
+	}
+	return spec.Origin.Credentials == styraclient.StringValue(current.Origin.Credentials) &&
+		spec.Origin.Path == styraclient.StringValue(current.Origin.Path) &&
+		spec.Origin.Reference == styraclient.StringValue(current.Origin.Reference) &&
+		spec.Origin.URL == styraclient.StringValue(current.Origin.URL)
 }
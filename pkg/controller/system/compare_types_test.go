@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestIsEqualExtra(t *testing.T) {
+	cases := map[string]struct {
+		spec    *runtime.RawExtension
+		current interface{}
+		want    bool
+	}{
+		"BothUnset": {
+			want: true,
+		},
+		"SpecUnset": {
+			current: map[string]interface{}{"failurePolicy": "Ignore"},
+			want:    false,
+		},
+		"Equal": {
+			spec: &runtime.RawExtension{Raw: []byte(`{"injection":{"enabled":true,"namespaces":["a","b"]},"failurePolicy":"Ignore"}`)},
+			current: map[string]interface{}{
+				"failurePolicy": "Ignore",
+				"injection": map[string]interface{}{
+					"namespaces": []interface{}{"a", "b"},
+					"enabled":    true,
+				},
+			},
+			want: true,
+		},
+		"ValueDiffers": {
+			spec:    &runtime.RawExtension{Raw: []byte(`{"failurePolicy": "Fail"}`)},
+			current: map[string]interface{}{"failurePolicy": "Ignore"},
+			want:    false,
+		},
+		"NumbersEqual": {
+			spec:    &runtime.RawExtension{Raw: []byte(`{"replicas": 2.0}`)},
+			current: map[string]interface{}{"replicas": float64(2)},
+			want:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := isEqualExtra(tc.spec, tc.current)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
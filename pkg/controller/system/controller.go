@@ -23,17 +23,18 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
 	"github.com/google/go-cmp/cmp"
-	"github.com/iancoleman/strcase"
 
 	"github.com/pkg/errors"
-	"k8s.io/utils/strings/slices"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
@@ -49,6 +50,7 @@ import (
 	"github.com/mistermx/styra-go-client/pkg/client/systems"
 	"github.com/mistermx/styra-go-client/pkg/models"
 
+	datasourcev1alpha1 "github.com/crossplane-contrib/provider-styra/apis/datasource/v1alpha1"
 	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
 	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
 	"github.com/crossplane-contrib/provider-styra/pkg/interface/controller"
@@ -64,42 +66,57 @@ const (
 	errGetAsset                 = "cannot get asset"
 	errIsUpToDateFailed         = "isUpToDate failed"
 	errGetLabels                = "cannot get system labels"
-	errGetLabelsInvalidResponse = "get system labels returned an unexpected response"
 	errCompareLabels            = "cannot compare labels"
 	errUpdateLabels             = "cannotUpdateLabels"
-	errMarshalHelmValues        = "cannot re-marshal helm values"
+	errMarshalAsset             = "cannot re-marshal asset"
 	errMarshalConnectionDetails = "cannot re-marshal connection details"
 	errExtractCert              = "cannot extract certificate from connection details"
 	errParseCert                = "cannot parse certificate"
+	errNoPEMBlock               = "no PEM block found"
+	errMarshalInstructions      = "cannot marshal install instructions"
+	errListSystems              = "cannot list systems"
+	errAmbiguousSystems         = "cannot adopt system: %d existing systems match"
+
+	pemPrefix = "-----BEGIN"
+
+	reasonRotateCertificate event.Reason = "RotateCertificate"
+	reasonLabelsRejected    event.Reason = "RejectedLabels"
+	msgRotateCertificate                 = "Published connection details with a new OPA certificate: expiry changed from %s to %s"
 )
 
 // SetupSystem adds a controller that reconciles Systems.
 func SetupSystem(mgr ctrl.Manager, o controller.Options) error {
 	name := managed.ControllerName(v1alpha1.SystemGroupKind)
 
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.SystemGroupVersionKind),
+		managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), newClientFn: styra.New, recorder: recorder}),
+		managed.WithInitializers(managed.NewDefaultProviderConfig(mgr.GetClient())),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(o.ConnectionPublisher...))
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
 		For(&v1alpha1.System{}).
-		Complete(managed.NewReconciler(mgr,
-			resource.ManagedKind(v1alpha1.SystemGroupVersionKind),
-			managed.WithExternalConnecter(&connector{kube: mgr.GetClient(), newClientFn: styra.New}),
-			managed.WithInitializers(managed.NewDefaultProviderConfig(mgr.GetClient())),
-			managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
-			managed.WithPollInterval(o.PollInterval),
-			managed.WithLogger(o.Logger.WithValues("controller", name)),
-			managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-			managed.WithConnectionPublishers(o.ConnectionPublisher...)))
+		Complete(&renewalReconciler{Reconciler: r, kube: mgr.GetClient()})
 }
 
 type connector struct {
 	kube        client.Client
 	newClientFn func(transport runtime.ClientTransport, formats strfmt.Registry) *styra.StyraAPI
+	recorder    event.Recorder
 }
 
 type external struct {
-	client *styra.StyraAPI
-	kube   client.Client
+	client   *styra.StyraAPI
+	kube     client.Client
+	recorder event.Recorder
 }
 
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
@@ -115,7 +132,7 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 
 	client := c.newClientFn(cfg, strfmt.Default)
 
-	return &external{client, c.kube}, nil
+	return &external{client, c.kube, c.recorder}, nil
 }
 
 func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -124,8 +141,18 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotSystem)
 	}
 
+	adopted := false
 	if meta.GetExternalName(cr) == "" {
-		return managed.ExternalObservation{}, nil
+		if !cr.Spec.ForProvider.AdoptExisting {
+			return managed.ExternalObservation{}, nil
+		}
+		id, err := e.findExisting(ctx, cr)
+		if err != nil || id == "" {
+			return managed.ExternalObservation{}, err
+		}
+		meta.SetExternalName(cr, id)
+		cr.SetConditions(v1alpha1.Adopted(id))
+		adopted = true
 	}
 
 	req := &systems.GetSystemParams{
@@ -138,7 +165,14 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	}
 
 	currentSpec := cr.Spec.ForProvider.DeepCopy()
+	lastAgents := cr.Status.AtProvider.Agents
+	lastBundle := cr.Status.AtProvider.Bundle
+	lastValidation := cr.Status.AtProvider.Validation
+	rejectedLabelsHash := cr.Status.AtProvider.RejectedLabelsHash
+	sourceControlHash := cr.Status.AtProvider.SourceControlHash
 	generateSystem(resp.Payload.Result).Status.AtProvider.DeepCopyInto(&cr.Status.AtProvider)
+	cr.Status.AtProvider.RejectedLabelsHash = rejectedLabelsHash
+	cr.Status.AtProvider.SourceControlHash = sourceControlHash
 
 	e.LateInitialize(cr, resp.Payload.Result)
 	isUpToDate, err := e.isUpToDate(ctx, cr, resp)
@@ -146,16 +180,24 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.Wrap(err, errIsUpToDateFailed)
 	}
 
-	cr.Status.SetConditions(v1.Available())
+	cr.Status.SetConditions(v1.Available(), typeCondition(cr), datasourcesCondition(cr.Status.AtProvider.Datasources))
+	e.observeAgents(ctx, cr, lastAgents)
+	e.observeSourceControl(ctx, cr)
+	isBundleUpToDate, err := e.observeBundle(ctx, cr, lastBundle)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errIsUpToDateFailed)
+	}
+	e.observeValidation(ctx, cr, lastValidation)
 
 	connectionDetails, err := e.getConnectionDetails(ctx, cr)
 	if err != nil {
 		return managed.ExternalObservation{}, errors.Wrap(err, errGetConnectionDetails)
 	}
 	externalObs := managed.ExternalObservation{
-		ResourceExists:          true,
-		ResourceUpToDate:        isUpToDate,
-		ResourceLateInitialized: !cmp.Equal(&cr.Spec.ForProvider, currentSpec),
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate && isBundleUpToDate,
+		// Set this to store the external name of an adopted system.
+		ResourceLateInitialized: adopted || !cmp.Equal(&cr.Spec.ForProvider, currentSpec),
 	}
 	shouldPublishConnectionDetails, hash, err := shouldPublishConnectionDetails(cr, connectionDetails)
 	if err != nil {
@@ -171,7 +213,11 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 			return managed.ExternalObservation{}, errors.Wrap(err, errExtractCert)
 		}
 		if cert != nil {
+			if lastNotAfter := cr.GetLastPublishedConnectionDetailsCertNotAfter(); !lastNotAfter.IsZero() && !lastNotAfter.Equal(cert.NotAfter.Truncate(time.Second)) {
+				e.recorder.Event(cr, event.Normal(reasonRotateCertificate, fmt.Sprintf(msgRotateCertificate, lastNotAfter.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))))
+			}
 			cr.SetLastPublishedConnectionDetailsCertNotAfter(cert.NotAfter)
+			cr.SetLastPublishedConnectionDetailsCertNotBefore(cert.NotBefore)
 		}
 		cr.SetLastPublishedConnectionDetailsHash(hash)
 		externalObs.ResourceLateInitialized = true // Set this to update the hash annotation.
@@ -179,11 +225,43 @@ func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 	return externalObs, nil
 }
 
+// findExisting returns the ID of the only existing system that matches cr by
+// name or external ID. It returns an empty ID if no system matches.
+func (e *external) findExisting(ctx context.Context, cr *v1alpha1.System) (string, error) {
+	resp, err := e.client.Systems.ListSystems(&systems.ListSystemsParams{
+		Context: ctx,
+		Type:    styraclient.String(cr.Spec.ForProvider.Type),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, errListSystems)
+	}
+
+	externalID := styraclient.StringValue(cr.Spec.ForProvider.ExternalID)
+	ids := []string{}
+	for _, s := range resp.Payload.Result {
+		if s == nil {
+			continue
+		}
+		if styraclient.StringValue(s.Name) == cr.GetName() || (externalID != "" && s.ExternalID == externalID) {
+			ids = append(ids, styraclient.StringValue(s.ID))
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", nil
+	case 1:
+		return ids[0], nil
+	}
+	cr.SetConditions(v1alpha1.AdoptionAmbiguous(ids))
+	return "", errors.Errorf(errAmbiguousSystems, len(ids))
+}
+
 func (e *external) isUpToDate(ctx context.Context, cr *v1alpha1.System, resp *systems.GetSystemOK) (bool, error) { // nolint:gocyclo
 	if cr.ObjectMeta.Name != styraclient.StringValue(resp.Payload.Result.Name) {
 		return false, nil
 	}
-	if cr.Spec.ForProvider.DeploymentParameters != nil && !isEqualSystemDeploymentParameters(cr.Spec.ForProvider.DeploymentParameters, resp.Payload.Result.DeploymentParameters) {
+	if cr.Spec.ForProvider.GetTypeInfo().DeploymentParameters && cr.Spec.ForProvider.DeploymentParameters != nil && !isEqualSystemDeploymentParameters(cr.Spec.ForProvider.DeploymentParameters, resp.Payload.Result.DeploymentParameters) {
 		return false, nil
 	}
 	if styraclient.StringValue(cr.Spec.ForProvider.Description) != resp.Payload.Result.Description {
@@ -195,9 +273,15 @@ func (e *external) isUpToDate(ctx context.Context, cr *v1alpha1.System, resp *sy
 	if cr.Spec.ForProvider.ReadOnly != nil && !styraclient.IsEqualBool(cr.Spec.ForProvider.ReadOnly, resp.Payload.Result.ReadOnly) {
 		return false, nil
 	}
+	if cr.Spec.ForProvider.SourceControl != nil && !isEqualSourceControlConfig(cr.Spec.ForProvider.SourceControl, resp.Payload.Result.SourceControl) {
+		return false, nil
+	}
 	if cr.Spec.ForProvider.Type != styraclient.StringValue(resp.Payload.Result.Type) {
 		return false, nil
 	}
+	if upToDate, err := e.arePoliciesUpToDate(ctx, cr); err != nil || !upToDate {
+		return false, err
+	}
 	return e.areLabelsUpToDate(ctx, cr)
 }
 
@@ -205,6 +289,10 @@ func (e *external) areLabelsUpToDate(ctx context.Context, cr *v1alpha1.System) (
 	if !cr.Spec.ForProvider.HasLabels() {
 		return true, nil
 	}
+	if isLabelsRejectionCurrent(cr) {
+		// Writing the same labels again would only be rejected again.
+		return true, nil
+	}
 
 	req := &policies.GetPolicyParams{
 		Context: ctx,
@@ -212,31 +300,18 @@ func (e *external) areLabelsUpToDate(ctx context.Context, cr *v1alpha1.System) (
 	}
 
 	res, err := e.client.Policies.GetPolicy(req)
+	if styraclient.IsPolicyNotFound(err) {
+		// The labels policy has been deleted, e.g. in the Styra UI. Update
+		// creates it again.
+		return false, nil
+	}
 	if err != nil {
 		return false, errors.Wrap(err, errGetLabels)
 	}
 
-	result, ok := res.Payload.Result.(map[string]interface{})
-	if !ok {
-		return false, errors.New(errGetLabelsInvalidResponse)
-	}
-
-	if _, exists := result["modules"]; !exists {
-		return false, errors.New(errGetLabelsInvalidResponse)
-	}
-
-	modules, ok := result["modules"].(map[string]interface{})
-	if !ok {
-		return false, errors.New(errGetLabelsInvalidResponse)
-	}
-
-	if _, exists := modules["labels.rego"]; !exists {
-		return false, errors.New(errGetLabelsInvalidResponse)
-	}
-
-	labelsModule, ok := modules["labels.rego"].(string)
+	labelsModule, ok := styraclient.GetPolicyModule(res, "labels.rego")
 	if !ok {
-		return false, errors.New(errGetLabelsInvalidResponse)
+		return false, nil
 	}
 
 	labelsAreEqual, err := compareLabels(ctx, labelsModule, cr)
@@ -265,8 +340,8 @@ func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 
 	meta.SetExternalName(cr, styraclient.StringValue(resp.Payload.Result.ID))
 
-	// Do not create/update labels and connection details here because an error
-	// will result in a recreation of the system.
+	// Do not create/update labels, source control and connection details here
+	// because an error will result in a recreation of the system.
 	// This shall be handled in Update().
 
 	return managed.ExternalCreation{
@@ -291,13 +366,63 @@ func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
 	}
 
-	if err := e.updateLabels(ctx, cr); err != nil {
+	if err := e.syncLabels(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
+	}
+
+	if err := e.updatePolicies(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
+	}
+
+	if err := e.deployBundle(ctx, cr); err != nil {
 		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateFailed)
 	}
 
 	return managed.ExternalUpdate{}, nil
 }
 
+// syncLabels writes the labels of cr to Styra if its system type supports
+// labels.
+func (e *external) syncLabels(ctx context.Context, cr *v1alpha1.System) error {
+	if !cr.Spec.ForProvider.HasLabels() {
+		return nil
+	}
+
+	err := e.updateLabels(ctx, cr)
+	switch {
+	case err == nil:
+		cr.SetConditions(v1alpha1.LabelsSynced())
+		cr.Status.AtProvider.RejectedLabelsHash = ""
+	case isLabelsRejected(err):
+		// Do not fail the whole update if Styra does not accept labels for
+		// the system. Report it instead so that the labels are not ignored
+		// silently.
+		cr.SetConditions(v1alpha1.LabelsRejected(err))
+		cr.Status.AtProvider.RejectedLabelsHash = hashLabels(cr.Spec.ForProvider.Labels)
+		e.recorder.Event(cr, event.Warning(reasonLabelsRejected, err))
+	default:
+		return err
+	}
+	return nil
+}
+
+// isLabelsRejectionCurrent returns whether Styra has rejected the current
+// labels of cr.
+func isLabelsRejectionCurrent(cr *v1alpha1.System) bool {
+	if cr.GetCondition(v1alpha1.TypeLabelsSynced).Reason != v1alpha1.ReasonLabelsRejected {
+		return false
+	}
+	return cr.Status.AtProvider.RejectedLabelsHash == hashLabels(cr.Spec.ForProvider.Labels)
+}
+
+// hashLabels returns a hash of labels. The keys of labels are sorted when
+// they are marshaled.
+func hashLabels(labels map[string]string) string {
+	raw, _ := json.Marshal(labels) //nolint:errchkjson // Cannot fail for a map of strings.
+	sum := sha1.Sum(raw)           //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
 func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 	cr, ok := mg.(*v1alpha1.System)
 	if !ok {
@@ -314,7 +439,7 @@ func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
 
 	_, err := e.client.Systems.DeleteSystem(req)
 	if err != nil {
-		return errors.Wrap(err, errDeleteFailed)
+		return errors.Wrap(resource.Ignore(isNotFound, err), errDeleteFailed)
 	}
 
 	return nil
@@ -326,7 +451,41 @@ func (e *external) LateInitialize(cr *v1alpha1.System, resp *models.SystemsV1Sys
 	cr.Spec.ForProvider.Description = styraclient.LateInitializeStringPtr(cr.Spec.ForProvider.Description, system.Spec.ForProvider.Description)
 	cr.Spec.ForProvider.ExternalID = styraclient.LateInitializeStringPtr(cr.Spec.ForProvider.ExternalID, system.Spec.ForProvider.ExternalID)
 	cr.Spec.ForProvider.ReadOnly = styraclient.LateInitializeBoolPtr(cr.Spec.ForProvider.ReadOnly, system.Spec.ForProvider.ReadOnly)
-	cr.Spec.ForProvider.DeploymentParameters = lateInitializeDeploymentParameters(cr.Spec.ForProvider.DeploymentParameters, system.Spec.ForProvider.DeploymentParameters)
+	cr.Spec.ForProvider.SourceControl = lateInitializeSourceControlConfig(cr.Spec.ForProvider.SourceControl, system.Spec.ForProvider.SourceControl)
+	if cr.Spec.ForProvider.GetTypeInfo().DeploymentParameters {
+		cr.Spec.ForProvider.DeploymentParameters = lateInitializeDeploymentParameters(cr.Spec.ForProvider.DeploymentParameters, system.Spec.ForProvider.DeploymentParameters)
+	}
+}
+
+// datasourcesCondition returns the DatasourcesHealthy condition for the given
+// datasources. Failing optional datasources are ignored.
+func datasourcesCondition(datasources []*v1alpha1.V1DatasourceConfig) v1.Condition {
+	failing := []string{}
+	for _, ds := range datasources {
+		if ds == nil || ds.Optional || ds.Status == nil {
+			continue
+		}
+		if ds.Status.Code == datasourcev1alpha1.DataSourceStatusFailed {
+			failing = append(failing, ds.ID)
+		}
+	}
+	if len(failing) > 0 {
+		return v1alpha1.DatasourcesFailing(failing)
+	}
+	return v1alpha1.DatasourcesHealthy()
+}
+
+// typeCondition validates the parameters of cr against the capabilities of
+// its system type.
+func typeCondition(cr *v1alpha1.System) v1.Condition {
+	info, known := v1alpha1.LookupSystemType(cr.Spec.ForProvider.Type)
+	if !known {
+		return v1alpha1.SystemTypeUnknown(cr.Spec.ForProvider.Type)
+	}
+	if !info.DeploymentParameters && cr.Spec.ForProvider.DeploymentParameters != nil {
+		return v1alpha1.UnsupportedParameters(cr.Spec.ForProvider.Type, "deploymentParameters")
+	}
+	return v1alpha1.SystemTypeKnown()
 }
 
 // shouldPublishConnectionDetails determines whether the connection details
@@ -334,7 +493,7 @@ func (e *external) LateInitialize(cr *v1alpha1.System, resp *models.SystemsV1Sys
 // Publishing should happen in the following cases:
 //  1. The details change but not the ever-changing values like CACert, Cert and
 //     key that are different evertime the Styra API is called.
-//  2. The Cert has expired.
+//  2. The Cert has expired or expires within connectionDetailsRenewBefore.
 //  3. Details have never been published.
 func shouldPublishConnectionDetails(cr *v1alpha1.System, details managed.ConnectionDetails) (bool, string, error) {
 	pruned, err := pruneConnectionDetails(cr, details)
@@ -352,51 +511,109 @@ func shouldPublishConnectionDetails(cr *v1alpha1.System, details managed.Connect
 		return true, hash, nil
 	}
 
-	// Check if the last published cert has expired
-	lastPublishedCertNotAfter := cr.GetLastPublishedConnectionDetailsCertNotAfter()
-	return lastPublishedCertNotAfter.Before(time.Now()), lastPublishedHash, nil
+	// Check if the last published cert has expired or is about to expire
+	return !cr.GetConnectionDetailsRenewalTime().After(time.Now()), lastPublishedHash, nil
 }
 
 func getCertFromConnectionDetails(cr *v1alpha1.System, details managed.ConnectionDetails) (*x509.Certificate, error) {
-	assetTypes := cr.Spec.ForProvider.GetAssetTypes()
-	if slices.Contains(assetTypes, v1alpha1.SystemAssetTypeHelmValues) {
-		key := strcase.ToLowerCamel(v1alpha1.SystemAssetTypeHelmValues)
-		helmValuesRaw, exists := details[key]
+	for _, a := range cr.Spec.ForProvider.GetAssets() {
+		var getCert func([]byte) (*x509.Certificate, error)
+		switch a.Type {
+		case v1alpha1.SystemAssetTypeHelmValues:
+			getCert = getCertFromHelmValues
+		case v1alpha1.SystemAssetTypeOpaConfig:
+			getCert = getCertFromOpaConfig
+		default:
+			continue
+		}
+		raw, exists := details[a.GetKey()]
 		if !exists {
-			return nil, nil
+			continue
 		}
-		type helmValuesTyped struct {
-			Opa *struct {
-				Cert *string `json:"Cert,omitempty"`
-			} `json:"opa,omitempty"`
+		raw, err := decodeAsset(a, raw)
+		if err != nil {
+			// Ignore assets that cannot be decoded like assets that cannot
+			// be parsed.
+			continue
 		}
-		helmValues := helmValuesTyped{}
-		if err := yaml.Unmarshal(helmValuesRaw, &helmValues); err != nil {
-			// Values might not be in the YAML format.
-			// Instead of failing everytime, we should just silently ignore this.
-			return nil, nil //nolint:nilerr
+		cert, err := getCert(raw)
+		if cert != nil || err != nil {
+			return cert, err
 		}
-		if helmValues.Opa == nil || helmValues.Opa.Cert == nil {
-			return nil, nil
+	}
+	return nil, nil
+}
+
+func getCertFromHelmValues(helmValuesRaw []byte) (*x509.Certificate, error) {
+	type helmValuesTyped struct {
+		Opa *struct {
+			Cert *string `json:"Cert,omitempty"`
+		} `json:"opa,omitempty"`
+	}
+	helmValues := helmValuesTyped{}
+	if err := yaml.Unmarshal(helmValuesRaw, &helmValues); err != nil {
+		// Values might not be in the YAML format.
+		// Instead of failing everytime, we should just silently ignore this.
+		return nil, nil //nolint:nilerr
+	}
+	if helmValues.Opa == nil || helmValues.Opa.Cert == nil {
+		return nil, nil
+	}
+	cert, err := parseCertificate(*helmValues.Opa.Cert)
+	return cert, errors.Wrap(err, errParseCert)
+}
+
+// getCertFromOpaConfig returns the first client certificate of a service in
+// the OPA config. Certificates that are referenced by a file path are
+// ignored.
+func getCertFromOpaConfig(opaConfigRaw []byte) (*x509.Certificate, error) {
+	opaConfig := map[string]interface{}{}
+	if err := yaml.Unmarshal(opaConfigRaw, &opaConfig); err != nil {
+		// Config might not be in the YAML format.
+		return nil, nil //nolint:nilerr
+	}
+	for _, svc := range opaConfigServices(opaConfig) {
+		clientTLS, ok := nestedMap(svc, "credentials", "client_tls")
+		if !ok {
+			continue
+		}
+		val, ok := clientTLS["cert"].(string)
+		if !ok || !isPEMOrBase64PEM(val) {
+			continue
 		}
-		cert, err := parseCertificate(*helmValues.Opa.Cert)
+		cert, err := parseCertificate(val)
 		return cert, errors.Wrap(err, errParseCert)
 	}
-	// TODO:
-	// case slices.Contains(assetTypes, v1alpha1.SystemAssetTypeOpaConfig):
 	return nil, nil
 }
 
-// parseCertificate from a base64 encoded PEM block.
-func parseCertificate(certPemBase64 string) (*x509.Certificate, error) {
-	certPem, err := base64.StdEncoding.DecodeString(certPemBase64)
-	if err != nil {
-		return nil, err
+// parseCertificate from a PEM block that might be base64 encoded.
+func parseCertificate(certPem string) (*x509.Certificate, error) {
+	raw := []byte(certPem)
+	if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte(pemPrefix)) {
+		decoded, err := base64.StdEncoding.DecodeString(certPem)
+		if err != nil {
+			return nil, err
+		}
+		raw = decoded
+	}
+	certRaw, _ := pem.Decode(raw)
+	if certRaw == nil {
+		return nil, errors.New(errNoPEMBlock)
 	}
-	certRaw, _ := pem.Decode(certPem)
 	return x509.ParseCertificate(certRaw.Bytes)
 }
 
+// isPEMOrBase64PEM returns whether val is a PEM block or a base64 encoded PEM
+// block and not a file path.
+func isPEMOrBase64PEM(val string) bool {
+	if strings.HasPrefix(strings.TrimSpace(val), pemPrefix) {
+		return true
+	}
+	decoded, err := base64.StdEncoding.DecodeString(val)
+	return err == nil && bytes.HasPrefix(bytes.TrimSpace(decoded), []byte(pemPrefix))
+}
+
 // pruneConnectionDetails removes all ever-changing fields from the connection
 // details.
 func pruneConnectionDetails(cr *v1alpha1.System, details managed.ConnectionDetails) (managed.ConnectionDetails, error) {
@@ -405,39 +622,111 @@ func pruneConnectionDetails(cr *v1alpha1.System, details managed.ConnectionDetai
 		prunedDetails[k] = v
 	}
 
-	assetTypes := cr.Spec.ForProvider.GetAssetTypes()
-	if slices.Contains(assetTypes, v1alpha1.SystemAssetTypeHelmValues) {
-		key := strcase.ToLowerCamel(v1alpha1.SystemAssetTypeHelmValues)
-		helmValuesRaw, exists := details[key]
+	volatileFields := cr.Spec.ForProvider.GetTypeInfo().VolatileFields
+	for _, a := range cr.Spec.ForProvider.GetAssets() {
+		fields := volatileFields[a.Type]
+		if len(fields) == 0 {
+			continue
+		}
+		raw, exists := details[a.GetKey()]
 		if !exists {
-			return prunedDetails, nil
+			continue
 		}
-		helmValues := map[string]interface{}{}
-		if err := yaml.Unmarshal(helmValuesRaw, &helmValues); err != nil {
-			// Values might not be in the YAML format.
-			// Instead of failing everytime, we should just silently ignore this.
-			return prunedDetails, nil //nolint:nilerr
+		raw, err := decodeAsset(a, raw)
+		if err != nil {
+			continue
 		}
+		pruned, err := pruneAsset(raw, fields)
+		if err != nil {
+			return nil, err
+		}
+		prunedDetails[a.GetKey()] = pruned
+	}
+	return prunedDetails, nil
+}
+
+// pruneAsset removes the given volatile fields from a YAML or JSON asset.
+// Assets in other formats are not pruned.
+func pruneAsset(assetRaw []byte, fields []string) ([]byte, error) {
+	asset := map[string]interface{}{}
+	if err := yaml.Unmarshal(assetRaw, &asset); err != nil {
+		// Assets might not be in the YAML format.
+		// Instead of failing everytime, we should just silently ignore this.
+		return assetRaw, nil //nolint:nilerr
+	}
+
+	// Delete properties that are changing on every call to the Styra API
+	// before calculating the hash.
+	for _, f := range fields {
+		pruneField(asset, strings.Split(f, "."))
+	}
 
-		// Delete properties that are changing on every call to the Styra API
-		// before calculating the hash.
-		if helmValues["opa"] != nil {
-			if opa, ok := helmValues["opa"].(map[string]interface{}); ok {
-				opa["Cert"] = nil
-				opa["CACert"] = nil
-				opa["Key"] = nil
+	pruned, err := yaml.Marshal(asset)
+	return pruned, errors.Wrap(err, errMarshalAsset)
+}
+
+// pruneField sets the field found under path in obj to nil. A * in path
+// matches every element of a list or map.
+func pruneField(obj interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for _, e := range v {
+				pruneField(e, path[1:])
 			}
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = nil
+			return
+		}
+		pruneField(v[path[0]], path[1:])
+	case []interface{}:
+		if path[0] != "*" {
+			return
 		}
+		for _, e := range v {
+			pruneField(e, path[1:])
+		}
+	}
+}
 
-		helmValuesRaw, err := yaml.Marshal(helmValues)
-		if err != nil {
-			return nil, errors.Wrap(err, errMarshalHelmValues)
+// opaConfigServices returns the services of an OPA config. OPA accepts them
+// both as a list and as a map keyed by service name.
+func opaConfigServices(opaConfig map[string]interface{}) []map[string]interface{} {
+	var services []map[string]interface{}
+	switch v := opaConfig["services"].(type) {
+	case []interface{}:
+		for _, svc := range v {
+			if m, ok := svc.(map[string]interface{}); ok {
+				services = append(services, m)
+			}
+		}
+	case map[string]interface{}:
+		for _, svc := range v {
+			if m, ok := svc.(map[string]interface{}); ok {
+				services = append(services, m)
+			}
 		}
-		prunedDetails[key] = helmValuesRaw
 	}
-	// TODO:
-	// case slices.Contains(assetTypes, v1alpha1.SystemAssetTypeOpaConfig):
-	return prunedDetails, nil
+	return services
+}
+
+// nestedMap returns the map that is found in obj under the given path of
+// keys.
+func nestedMap(obj map[string]interface{}, keys ...string) (map[string]interface{}, bool) {
+	cur := obj
+	for _, k := range keys {
+		next, ok := cur[k].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		cur = next
+	}
+	return cur, true
 }
 
 func (e *external) getConnectionDetails(ctx context.Context, cr *v1alpha1.System) (managed.ConnectionDetails, error) {
@@ -447,20 +736,30 @@ func (e *external) getConnectionDetails(ctx context.Context, cr *v1alpha1.System
 
 	details := managed.ConnectionDetails{}
 
-	for _, assetType := range cr.Spec.ForProvider.GetAssetTypes() {
-		resp, err := e.getAsset(ctx, cr, assetType)
+	for _, a := range cr.Spec.ForProvider.GetAssets() {
+		resp, err := e.getAssetContent(ctx, cr, a.Type)
 
 		if err != nil {
-			return nil, errors.Wrapf(err, "cannot get %s", assetType)
+			return nil, errors.Wrapf(err, "cannot get %s", a.Type)
 		}
 
-		key := strcase.ToLowerCamel(assetType)
-		details[key] = resp
+		details[a.GetKey()] = encodeAsset(a, resp)
 	}
 
 	return details, nil
 }
 
+// getAssetContent gets the content of an asset of the given type.
+func (e *external) getAssetContent(ctx context.Context, cr *v1alpha1.System, at string) ([]byte, error) {
+	switch at {
+	case v1alpha1.SystemAssetTypeOpaDiscoveryConfig:
+		return e.getOpaDiscoveryConfig(ctx, cr)
+	case v1alpha1.SystemAssetTypeInstallInstructions:
+		return e.getInstallInstructions(ctx, cr)
+	}
+	return e.getAsset(ctx, cr, at)
+}
+
 func (e *external) getAsset(ctx context.Context, cr *v1alpha1.System, at string) ([]byte, error) {
 	req := &systems.GetAssetParams{
 		Context:   ctx,
@@ -477,6 +776,73 @@ func (e *external) getAsset(ctx context.Context, cr *v1alpha1.System, at string)
 	return buffer.Bytes(), nil
 }
 
+// getOpaDiscoveryConfig gets the discovery bundle of the system.
+func (e *external) getOpaDiscoveryConfig(ctx context.Context, cr *v1alpha1.System) ([]byte, error) {
+	req := &systems.GetOPADiscoveryConfigParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+	}
+
+	// The response is a gzipped bundle and not the JSON document described by
+	// the API spec.
+	buffer := bytes.Buffer{}
+	consumer := runtime.ConsumerFunc(func(r io.Reader, _ interface{}) error {
+		_, err := buffer.ReadFrom(r)
+		return err
+	})
+	_, err := e.client.Systems.GetOPADiscoveryConfig(req, systems.ClientOption(styraclient.OverwriteConsumer(consumer)))
+	if err != nil {
+		return nil, errors.Wrap(err, errGetAsset)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// getInstallInstructions gets the install and uninstall instructions of the
+// system as JSON.
+func (e *external) getInstallInstructions(ctx context.Context, cr *v1alpha1.System) ([]byte, error) {
+	req := &systems.GetInstructionsParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+	}
+
+	resp, err := e.client.Systems.GetInstructions(req)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetAsset)
+	}
+
+	instructions, err := json.Marshal(resp.Payload.Result)
+	return instructions, errors.Wrap(err, errMarshalInstructions)
+}
+
+// encodeAsset encodes the content of an asset as configured.
+func encodeAsset(a v1alpha1.SystemAsset, content []byte) []byte {
+	if a.GetEncoding() != v1alpha1.SystemAssetEncodingBase64 {
+		return content
+	}
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(content)))
+	base64.StdEncoding.Encode(encoded, content)
+	return encoded
+}
+
+// decodeAsset reverts encodeAsset.
+func decodeAsset(a v1alpha1.SystemAsset, content []byte) ([]byte, error) {
+	if a.GetEncoding() != v1alpha1.SystemAssetEncodingBase64 {
+		return content, nil
+	}
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(content)))
+	n, err := base64.StdEncoding.Decode(decoded, content)
+	return decoded[:n], err
+}
+
+// isLabelsRejected returns whether Styra has refused to store the labels of
+// a system because it considers them invalid, e.g. because labels are not
+// supported for its type. Other errors, e.g. revoked credentials or a deleted
+// system, are not caused by the labels and must not be hidden.
+func isLabelsRejected(err error) bool {
+	return styraclient.ReasonFor(err) == styraclient.ErrorReasonInvalid
+}
+
 func (e *external) updateLabels(ctx context.Context, cr *v1alpha1.System) error {
 	rego, err := generateRegoLabels(cr)
 	if err != nil {
@@ -32,10 +32,12 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	"sigs.k8s.io/yaml"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -100,6 +102,61 @@ func withMockPolicies(t *testing.T, mod mockPolicyModifier) *mockpolicies.MockCl
 	return mock
 }
 
+// withMockLabelsPolicy returns a policies client that returns the labels
+// policy of a system of the given type with the test labels.
+func withMockLabelsPolicy(t *testing.T, systemType string) *mockpolicies.MockClientService {
+	return withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+		mcs.EXPECT().
+			GetPolicy(&policies.GetPolicyParams{
+				Policy:  fmt.Sprintf("metadata/%s/labels", testSystemID),
+				Context: context.Background(),
+			}).
+			Return(&policies.GetPolicyOK{
+				Payload: &models.PoliciesV1PolicyGetResponse{
+					Result: map[string]interface{}{
+						"modules": map[string]interface{}{
+							"labels.rego": fmt.Sprintf(testLabelsRego, systemType),
+						},
+					},
+				},
+			}, nil)
+	})
+}
+
+// expectNoAgents expects the agents of the test system to be requested and
+// returns none.
+func expectNoAgents(mcs *mocksystem.MockClientService) {
+	mcs.EXPECT().
+		GetSystemAgents(&systems.GetSystemAgentsParams{
+			System:  testSystemID,
+			Context: context.Background(),
+		}).
+		Return(&systems.GetSystemAgentsOK{
+			Payload: &models.SystemsV1SystemsGetAgentsResponse{},
+		}, nil)
+}
+
+// expectNoBundle expects the bundles of the test system to be requested and
+// returns none.
+func expectNoBundle(mcs *mocksystem.MockClientService) {
+	mcs.EXPECT().
+		GetSystemBundles(&systems.GetSystemBundlesParams{
+			System:  testSystemID,
+			Context: context.Background(),
+		}).
+		Return(&systems.GetSystemBundlesOK{
+			Payload: &models.SystemsV1SystemsGetBundlesResponse{},
+		}, nil)
+	mcs.EXPECT().
+		GetSystemBundleDeploy(&systems.GetSystemBundleDeployParams{
+			System:  testSystemID,
+			Context: context.Background(),
+		}).
+		Return(&systems.GetSystemBundleDeployOK{
+			Payload: &models.SystemsV1SystemsGetBundleDeployResponse{},
+		}, nil)
+}
+
 type SystemModifier func(*v1alpha1.System)
 
 func withName(v string) SystemModifier {
@@ -114,6 +171,18 @@ func withExternalName(v string) SystemModifier {
 	}
 }
 
+func withAgents(a *v1alpha1.SystemAgentsObservation) SystemModifier {
+	return func(r *v1alpha1.System) { r.Status.AtProvider.Agents = a }
+}
+
+func withBundle(b *v1alpha1.SystemBundleObservation) SystemModifier {
+	return func(r *v1alpha1.System) { r.Status.AtProvider.Bundle = b }
+}
+
+func withRejectedLabelsHash(h string) SystemModifier {
+	return func(r *v1alpha1.System) { r.Status.AtProvider.RejectedLabelsHash = h }
+}
+
 func withConditions(c ...xpv1.Condition) SystemModifier {
 	return func(r *v1alpha1.System) { r.Status.ConditionedStatus.Conditions = c }
 }
@@ -128,6 +197,12 @@ func withAnnotationCertExpire(t time.Time) SystemModifier {
 	}
 }
 
+func withAnnotationCertIssued(t time.Time) SystemModifier {
+	return func(s *v1alpha1.System) {
+		s.SetLastPublishedConnectionDetailsCertNotBefore(t)
+	}
+}
+
 func withAnnotationConnectionDetailsHash(hash string) SystemModifier {
 	return func(s *v1alpha1.System) {
 		s.SetLastPublishedConnectionDetailsHash(hash)
@@ -155,9 +230,11 @@ func TestObserve(t *testing.T) {
 		t.Error(err)
 	}
 	testCertExpire := time.Now().Add(24 * time.Hour)
+	testCertIssued := testCertExpire.Add(-48 * time.Hour)
 	testCert, err := x509.CreateCertificate(
 		rand,
 		&x509.Certificate{
+			NotBefore:    testCertIssued,
 			NotAfter:     testCertExpire,
 			SerialNumber: big.NewInt(1),
 		},
@@ -206,6 +283,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -217,6 +296,7 @@ func TestObserve(t *testing.T) {
 								return nil, nil
 							})
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
@@ -269,7 +349,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:   true,
@@ -297,6 +379,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -308,6 +392,7 @@ func TestObserve(t *testing.T) {
 								return nil, nil
 							})
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withExternalName(testSystemID),
@@ -346,7 +431,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:          true,
@@ -378,6 +465,210 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
+						mcs.EXPECT().
+							GetAsset(&systems.GetAssetParams{
+								Assettype: helmValuesAssetType,
+								System:    testSystemID,
+								Context:   context.Background(),
+							}, &bytes.Buffer{}, gomock.Any()).
+							DoAndReturn(func(params *systems.GetAssetParams, writer io.Writer, _ ...systems.ClientOption) (*systems.GetAssetOK, error) {
+								writer.Write([]byte(testAsset))
+								return nil, nil
+							})
+					}),
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							GetPolicy(&policies.GetPolicyParams{
+								Policy:  fmt.Sprintf("metadata/%s/labels", testSystemID),
+								Context: context.Background(),
+							}).
+							Return(&policies.GetPolicyOK{
+								Payload: &models.PoliciesV1PolicyGetResponse{
+									Result: map[string]interface{}{
+										"modules": map[string]interface{}{
+											"labels.rego": fmt.Sprintf(testLabelsRego, kubernetesV2Type),
+										},
+									},
+								},
+							}, nil)
+					}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+					withAnnotationCertExpire(testCertExpire),
+					withAnnotationConnectionDetailsHash("6926b9f92e20f006e67147fbed237a1a69886fb0"),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{},
+						},
+						Description: &testDescription,
+						DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+							HTTPProxy:                styraclient.String(""),
+							HTTPSProxy:               styraclient.String(""),
+							KubernetesVersion:        styraclient.String(""),
+							Namespace:                styraclient.String(""),
+							NoProxy:                  styraclient.String(""),
+							TimeoutSeconds:           styraclient.Int32(0),
+							TrustedContainerRegistry: styraclient.String(""),
+						},
+						ReadOnly:   styraclient.Bool(true),
+						Type:       kubernetesV2Type,
+						ExternalID: &testExternalID,
+					}),
+				),
+			},
+			want: want{
+				cr: System(
+					withAnnotationCertExpire(testCertExpire),
+					withAnnotationConnectionDetailsHash("6926b9f92e20f006e67147fbed237a1a69886fb0"),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{},
+						},
+						Description: &testDescription,
+						DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+							HTTPProxy:                styraclient.String(""),
+							HTTPSProxy:               styraclient.String(""),
+							KubernetesVersion:        styraclient.String(""),
+							Namespace:                styraclient.String(""),
+							NoProxy:                  styraclient.String(""),
+							TimeoutSeconds:           styraclient.Int32(0),
+							TrustedContainerRegistry: styraclient.String(""),
+						},
+						ReadOnly:   styraclient.Bool(true),
+						Type:       kubernetesV2Type,
+						ExternalID: &testExternalID,
+					}),
+					withExternalName(testSystemID),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeKnown(), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
+				),
+				result: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: false,
+				},
+			},
+		},
+		"LabelsRejected": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							GetSystem(&systems.GetSystemParams{
+								System:  testSystemID,
+								Context: context.Background(),
+							}).
+							Return(&systems.GetSystemOK{
+								Payload: &models.SystemsV1SystemsGetResponse{
+									Result: &models.SystemsV1SystemConfig{
+										Description:          testDescription,
+										DeploymentParameters: &models.SystemsV1SystemDeploymentParameters{},
+										ReadOnly:             styraclient.Bool(true),
+										Type:                 &kubernetesV2Type,
+										ExternalID:           testExternalID,
+									},
+								},
+							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
+						mcs.EXPECT().
+							GetAsset(&systems.GetAssetParams{
+								Assettype: helmValuesAssetType,
+								System:    testSystemID,
+								Context:   context.Background(),
+							}, &bytes.Buffer{}, gomock.Any()).
+							DoAndReturn(func(params *systems.GetAssetParams, writer io.Writer, _ ...systems.ClientOption) (*systems.GetAssetOK, error) {
+								writer.Write([]byte(testAsset))
+								return nil, nil
+							})
+					}),
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+					withAnnotationCertExpire(testCertExpire),
+					withAnnotationConnectionDetailsHash("6926b9f92e20f006e67147fbed237a1a69886fb0"),
+					withConditions(v1alpha1.LabelsRejected(errBoom)),
+					withRejectedLabelsHash("bf21a9e8fbc5a3846fb05b4fa0859e0917b2202f"),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{},
+						},
+						Description: &testDescription,
+						DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+							HTTPProxy:                styraclient.String(""),
+							HTTPSProxy:               styraclient.String(""),
+							KubernetesVersion:        styraclient.String(""),
+							Namespace:                styraclient.String(""),
+							NoProxy:                  styraclient.String(""),
+							TimeoutSeconds:           styraclient.Int32(0),
+							TrustedContainerRegistry: styraclient.String(""),
+						},
+						ReadOnly:   styraclient.Bool(true),
+						Type:       kubernetesV2Type,
+						ExternalID: &testExternalID,
+					}),
+				),
+			},
+			want: want{
+				cr: System(
+					withAnnotationCertExpire(testCertExpire),
+					withAnnotationConnectionDetailsHash("6926b9f92e20f006e67147fbed237a1a69886fb0"),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{},
+						},
+						Description: &testDescription,
+						DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+							HTTPProxy:                styraclient.String(""),
+							HTTPSProxy:               styraclient.String(""),
+							KubernetesVersion:        styraclient.String(""),
+							Namespace:                styraclient.String(""),
+							NoProxy:                  styraclient.String(""),
+							TimeoutSeconds:           styraclient.Int32(0),
+							TrustedContainerRegistry: styraclient.String(""),
+						},
+						ReadOnly:   styraclient.Bool(true),
+						Type:       kubernetesV2Type,
+						ExternalID: &testExternalID,
+					}),
+					withExternalName(testSystemID),
+					withConditions(v1alpha1.LabelsRejected(errBoom), xpv1.Available(), v1alpha1.SystemTypeKnown(), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withRejectedLabelsHash("bf21a9e8fbc5a3846fb05b4fa0859e0917b2202f"),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
+				),
+				result: managed.ExternalObservation{
+					ResourceExists:   true,
+					ResourceUpToDate: true,
+				},
+			},
+		},
+		"LabelsChangedAfterRejection": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							GetSystem(&systems.GetSystemParams{
+								System:  testSystemID,
+								Context: context.Background(),
+							}).
+							Return(&systems.GetSystemOK{
+								Payload: &models.SystemsV1SystemsGetResponse{
+									Result: &models.SystemsV1SystemConfig{
+										Description:          testDescription,
+										DeploymentParameters: &models.SystemsV1SystemDeploymentParameters{},
+										ReadOnly:             styraclient.Bool(true),
+										Type:                 &kubernetesV2Type,
+										ExternalID:           testExternalID,
+									},
+								},
+							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -410,6 +701,8 @@ func TestObserve(t *testing.T) {
 					withExternalName(testSystemID),
 					withAnnotationCertExpire(testCertExpire),
 					withAnnotationConnectionDetailsHash("6926b9f92e20f006e67147fbed237a1a69886fb0"),
+					withConditions(v1alpha1.LabelsRejected(errBoom)),
+					withRejectedLabelsHash("a5e744d0164540d33b1d7ea616c28f2fa97e754a"),
 					withSpec(v1alpha1.SystemParameters{
 						CustomSystemParameters: v1alpha1.CustomSystemParameters{
 							Labels: map[string]string{},
@@ -453,7 +746,10 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(v1alpha1.LabelsRejected(errBoom), xpv1.Available(), v1alpha1.SystemTypeKnown(), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withRejectedLabelsHash("a5e744d0164540d33b1d7ea616c28f2fa97e754a"),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:   true,
@@ -481,6 +777,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -492,6 +790,7 @@ func TestObserve(t *testing.T) {
 								return nil, nil
 							})
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withExternalName(testSystemID),
@@ -521,6 +820,7 @@ func TestObserve(t *testing.T) {
 			want: want{
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
+					withAnnotationCertIssued(testCertIssued),
 					withAnnotationConnectionDetailsHash("29f8555f8eebaeae98d709b9204ab481642f7c27"),
 					withSpec(v1alpha1.SystemParameters{
 						CustomSystemParameters: v1alpha1.CustomSystemParameters{
@@ -543,7 +843,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:          true,
@@ -575,6 +877,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -586,6 +890,7 @@ func TestObserve(t *testing.T) {
 								return nil, nil
 							})
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withExternalName(testSystemID),
@@ -615,6 +920,7 @@ func TestObserve(t *testing.T) {
 			want: want{
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
+					withAnnotationCertIssued(testCertIssued),
 					withAnnotationConnectionDetailsHash("29f8555f8eebaeae98d709b9204ab481642f7c27"),
 					withSpec(v1alpha1.SystemParameters{
 						CustomSystemParameters: v1alpha1.CustomSystemParameters{
@@ -637,7 +943,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:          true,
@@ -669,6 +977,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -680,6 +990,7 @@ func TestObserve(t *testing.T) {
 								return nil, nil
 							})
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
@@ -710,6 +1021,7 @@ func TestObserve(t *testing.T) {
 			want: want{
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
+					withAnnotationCertIssued(testCertIssued),
 					withAnnotationConnectionDetailsHash("13b5a8deaf7e6d77f020bef04c1668fbd2dff4e2"),
 					withSpec(v1alpha1.SystemParameters{
 						CustomSystemParameters: v1alpha1.CustomSystemParameters{
@@ -732,7 +1044,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:          true,
@@ -764,6 +1078,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -775,6 +1091,7 @@ func TestObserve(t *testing.T) {
 								return nil, nil
 							})
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
@@ -805,6 +1122,7 @@ func TestObserve(t *testing.T) {
 			want: want{
 				cr: System(
 					withAnnotationCertExpire(testCertExpire),
+					withAnnotationCertIssued(testCertIssued),
 					withAnnotationConnectionDetailsHash("13b5a8deaf7e6d77f020bef04c1668fbd2dff4e2"),
 					withSpec(v1alpha1.SystemParameters{
 						CustomSystemParameters: v1alpha1.CustomSystemParameters{
@@ -827,7 +1145,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{
 					ResourceExists:          true,
@@ -941,6 +1261,8 @@ func TestObserve(t *testing.T) {
 									},
 								},
 							}, nil)
+						expectNoAgents(mcs)
+						expectNoBundle(mcs)
 						mcs.EXPECT().
 							GetAsset(&systems.GetAssetParams{
 								Assettype: helmValuesAssetType,
@@ -949,6 +1271,7 @@ func TestObserve(t *testing.T) {
 							}, &bytes.Buffer{}, gomock.Any()).
 							Return(nil, errBoom)
 					}),
+					Policies: withMockLabelsPolicy(t, testType),
 				},
 				cr: System(
 					withExternalName(testSystemID),
@@ -997,7 +1320,9 @@ func TestObserve(t *testing.T) {
 						ExternalID: &testExternalID,
 					}),
 					withExternalName(testSystemID),
-					withConditions(xpv1.Available()),
+					withConditions(xpv1.Available(), v1alpha1.SystemTypeUnknown(testType), v1alpha1.DatasourcesHealthy(), v1alpha1.NoAgents(), v1alpha1.NoBundle()),
+					withAgents(&v1alpha1.SystemAgentsObservation{}),
+					withBundle(&v1alpha1.SystemBundleObservation{}),
 				),
 				result: managed.ExternalObservation{},
 				err:    errors.Wrap(errors.Wrap(errors.Wrap(errBoom, errGetAsset), "cannot get helm-values"), errGetConnectionDetails),
@@ -1007,13 +1332,13 @@ func TestObserve(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{client: &tc.styra}
+			e := &external{client: &tc.styra, recorder: event.NewNopRecorder()}
 			o, err := e.Observe(context.Background(), tc.args.cr)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("r: -want, +got:\n%s", diff)
 			}
-			if diff := cmp.Diff(tc.want.cr, tc.args.cr, test.EquateConditions()); diff != "" {
+			if diff := cmp.Diff(tc.want.cr, tc.args.cr, test.EquateConditions(), cmpopts.IgnoreFields(v1alpha1.SystemAgentsObservation{}, "LastObservedAt"), cmpopts.IgnoreFields(v1alpha1.SystemBundleObservation{}, "LastObservedAt")); diff != "" {
 				t.Errorf("r: -want, +got:\n%s", diff)
 			}
 			if diff := cmp.Diff(tc.want.result, o); diff != "" {
@@ -1153,7 +1478,7 @@ func TestCreate(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{client: &tc.styra}
+			e := &external{client: &tc.styra, recorder: event.NewNopRecorder()}
 			o, err := e.Create(context.Background(), tc.args.cr)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -1170,6 +1495,9 @@ func TestCreate(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
+	errLabelsRejected := &styraclient.APIError{Operation: "UpdatePolicy", StatusCode: 400}
+	errLabelsUnauthorized := &styraclient.APIError{Operation: "UpdatePolicy", StatusCode: 401}
+
 	type want struct {
 		cr     *v1alpha1.System
 		result managed.ExternalUpdate
@@ -1270,43 +1598,12 @@ func TestUpdate(t *testing.T) {
 						Type:       testType,
 						ExternalID: &testExternalID,
 					}),
+					withConditions(v1alpha1.LabelsSynced()),
 				),
 				result: managed.ExternalUpdate{},
 			},
 		},
-		"UpdateSystemFailed": {
-			args: args{
-				styra: styra.StyraAPI{
-					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
-						mcs.EXPECT().
-							UpdateSystem(&systems.UpdateSystemParams{
-								Body: &models.SystemsV1SystemsPutRequest{
-									Name: &testSystemName,
-									Type: &testType,
-								},
-								Context: context.Background(),
-							}).
-							Return(nil, errBoom)
-					}),
-				},
-				cr: System(
-					withName(testSystemName),
-					withSpec(v1alpha1.SystemParameters{
-						Type: testType,
-					}),
-				),
-			},
-			want: want{
-				cr: System(
-					withName(testSystemName),
-					withSpec(v1alpha1.SystemParameters{
-						Type: testType,
-					}),
-				),
-				err: errors.Wrap(errBoom, errUpdateFailed),
-			},
-		},
-		"UpdatePoliciesFailed": {
+		"LabelsRejected": {
 			args: args{
 				styra: styra.StyraAPI{
 					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
@@ -1342,7 +1639,7 @@ func TestUpdate(t *testing.T) {
 								},
 								Context: context.Background(),
 							}).
-							Return(nil, errBoom)
+							Return(nil, errLabelsRejected)
 					}),
 				},
 				cr: System(
@@ -1396,25 +1693,186 @@ func TestUpdate(t *testing.T) {
 						Type:       testType,
 						ExternalID: &testExternalID,
 					}),
+					withConditions(v1alpha1.LabelsRejected(errors.Wrap(errLabelsRejected, errUpdateLabels))),
+					withRejectedLabelsHash("a5e744d0164540d33b1d7ea616c28f2fa97e754a"),
 				),
 				result: managed.ExternalUpdate{},
-				err:    errors.Wrap(errors.Wrap(errBoom, errUpdateLabels), errUpdateFailed),
 			},
 		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			e := &external{client: &tc.styra}
-			u, err := e.Update(context.Background(), tc.args.cr)
-
-			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("r: -want, +got:\n%s", diff)
-			}
-			if diff := cmp.Diff(tc.want.cr, tc.args.cr, test.EquateConditions()); diff != "" {
-				t.Errorf("r: -want, +got:\n%s", diff)
-			}
-			if diff := cmp.Diff(tc.want.result, u); diff != "" {
+		"LabelsUnauthorized": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							UpdateSystem(gomock.Any()).
+							Return(&systems.UpdateSystemOK{}, nil)
+					}),
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							UpdatePolicy(gomock.Any()).
+							Return(nil, errLabelsUnauthorized)
+					}),
+				},
+				cr: System(
+					withName(testSystemName),
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+					}),
+				),
+			},
+			want: want{
+				cr: System(
+					withName(testSystemName),
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+					}),
+				),
+				err: errors.Wrap(errors.Wrap(errLabelsUnauthorized, errUpdateLabels), errUpdateFailed),
+			},
+		},
+		"UpdateSystemFailed": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							UpdateSystem(&systems.UpdateSystemParams{
+								Body: &models.SystemsV1SystemsPutRequest{
+									Name: &testSystemName,
+									Type: &testType,
+								},
+								Context: context.Background(),
+							}).
+							Return(nil, errBoom)
+					}),
+				},
+				cr: System(
+					withName(testSystemName),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+					}),
+				),
+			},
+			want: want{
+				cr: System(
+					withName(testSystemName),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+					}),
+				),
+				err: errors.Wrap(errBoom, errUpdateFailed),
+			},
+		},
+		"UpdatePoliciesFailed": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							UpdateSystem(&systems.UpdateSystemParams{
+								System: testSystemID,
+								Body: &models.SystemsV1SystemsPutRequest{
+									Description: testDescription,
+									DeploymentParameters: &models.SystemsV1SystemDeploymentParameters{
+										DenyOnOpaFail: styraclient.Bool(true),
+									},
+									Name:       &testSystemName,
+									ReadOnly:   styraclient.Bool(true),
+									Type:       &testType,
+									ExternalID: testExternalID,
+								},
+								Context: context.Background(),
+							}).
+							Return(&systems.UpdateSystemOK{
+								Payload: &models.SystemsV1SystemsPutResponse{
+									Result: &models.SystemsV1SystemConfig{},
+								},
+							}, nil)
+					}),
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							UpdatePolicy(&policies.UpdatePolicyParams{
+								Policy: fmt.Sprintf("metadata/%s/labels", testSystemID),
+								Body: &models.PoliciesV1PoliciesPutRequest{
+									Modules: map[string]string{
+										"labels.rego": fmt.Sprintf(testLabelsRego, testType),
+									},
+								},
+								Context: context.Background(),
+							}).
+							Return(nil, errBoom)
+					}),
+				},
+				cr: System(
+					withName(testSystemName),
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
+						Description: &testDescription,
+						DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+							DenyOnOpaFail:            styraclient.Bool(true),
+							HTTPProxy:                styraclient.String(""),
+							HTTPSProxy:               styraclient.String(""),
+							KubernetesVersion:        styraclient.String(""),
+							Namespace:                styraclient.String(""),
+							NoProxy:                  styraclient.String(""),
+							TimeoutSeconds:           styraclient.Int32(0),
+							TrustedContainerRegistry: styraclient.String(""),
+						},
+						ReadOnly:   styraclient.Bool(true),
+						Type:       testType,
+						ExternalID: &testExternalID,
+					}),
+				),
+			},
+			want: want{
+				cr: System(
+					withName(testSystemName),
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
+						Description: &testDescription,
+						DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+							DenyOnOpaFail:            styraclient.Bool(true),
+							HTTPProxy:                styraclient.String(""),
+							HTTPSProxy:               styraclient.String(""),
+							KubernetesVersion:        styraclient.String(""),
+							Namespace:                styraclient.String(""),
+							NoProxy:                  styraclient.String(""),
+							TimeoutSeconds:           styraclient.Int32(0),
+							TrustedContainerRegistry: styraclient.String(""),
+						},
+						ReadOnly:   styraclient.Bool(true),
+						Type:       testType,
+						ExternalID: &testExternalID,
+					}),
+				),
+				result: managed.ExternalUpdate{},
+				err:    errors.Wrap(errors.Wrap(errBoom, errUpdateLabels), errUpdateFailed),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := &external{client: &tc.styra, recorder: event.NewNopRecorder()}
+			u, err := e.Update(context.Background(), tc.args.cr)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.args.cr, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.result, u); diff != "" {
 				t.Errorf("r: -want, +got:\n%s", diff)
 			}
 		})
@@ -1476,11 +1934,33 @@ func TestDelete(t *testing.T) {
 				err: errors.Wrap(errBoom, errDeleteFailed),
 			},
 		},
+		"NotFound": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							DeleteSystem(&systems.DeleteSystemParams{
+								System:  testSystemID,
+								Context: context.Background(),
+							}).
+							Return(nil, &styraclient.APIError{Operation: "DeleteSystem", StatusCode: 404})
+					}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+				),
+			},
+			want: want{
+				cr: System(
+					withExternalName(testSystemID),
+				),
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{client: &tc.styra}
+			e := &external{client: &tc.styra, recorder: event.NewNopRecorder()}
 			err := e.Delete(context.Background(), tc.args.cr)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -1503,13 +1983,113 @@ func TestAreLabelsUpToDate(t *testing.T) {
 		args
 		want
 	}{
-		"CustomSystem": {
+		"LabelsPolicyNotFound": {
+			args: args{
+				styra: styra.StyraAPI{
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							GetPolicy(&policies.GetPolicyParams{
+								Policy:  fmt.Sprintf("metadata/%s/labels", testSystemID),
+								Context: context.Background(),
+							}).
+							Return(nil, &policies.GetPolicyNotFound{})
+					}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
+					}),
+				),
+			},
+			want: want{
+				false,
+				nil,
+			},
+		},
+		"LabelsModuleMissing": {
+			args: args{
+				styra: styra.StyraAPI{
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							GetPolicy(&policies.GetPolicyParams{
+								Policy:  fmt.Sprintf("metadata/%s/labels", testSystemID),
+								Context: context.Background(),
+							}).
+							Return(&policies.GetPolicyOK{
+								Payload: &models.PoliciesV1PolicyGetResponse{
+									Result: map[string]interface{}{
+										"modules": map[string]interface{}{},
+									},
+								},
+							}, nil)
+					}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
+					}),
+				),
+			},
+			want: want{
+				false,
+				nil,
+			},
+		},
+		"GetLabelsFailed": {
+			args: args{
+				styra: styra.StyraAPI{
+					Policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+						mcs.EXPECT().
+							GetPolicy(&policies.GetPolicyParams{
+								Policy:  fmt.Sprintf("metadata/%s/labels", testSystemID),
+								Context: context.Background(),
+							}).
+							Return(nil, errBoom)
+					}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						Type: testType,
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
+					}),
+				),
+			},
+			want: want{
+				false,
+				errors.Wrap(errBoom, errGetLabels),
+			},
+		},
+		"CustomSystemUpToDate": {
 			args: args{
-				styra: styra.StyraAPI{},
+				styra: styra.StyraAPI{
+					Policies: withMockLabelsPolicy(t, "custom"),
+				},
 				cr: System(
 					withExternalName(testSystemID),
 					withSpec(v1alpha1.SystemParameters{
 						Type: "custom",
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
 					}),
 				),
 			},
@@ -1518,9 +2098,11 @@ func TestAreLabelsUpToDate(t *testing.T) {
 				nil,
 			},
 		},
-		"KubernetesSystem": {
+		"KubernetesSystemNotUpToDate": {
 			args: args{
-				styra: styra.StyraAPI{},
+				styra: styra.StyraAPI{
+					Policies: withMockLabelsPolicy(t, "kubernetes"),
+				},
 				cr: System(
 					withExternalName(testSystemID),
 					withSpec(v1alpha1.SystemParameters{
@@ -1529,7 +2111,7 @@ func TestAreLabelsUpToDate(t *testing.T) {
 				),
 			},
 			want: want{
-				true,
+				false,
 				nil,
 			},
 		},
@@ -1609,7 +2191,9 @@ func TestAreLabelsUpToDate(t *testing.T) {
 		},
 		"KubernetesSystemV123": {
 			args: args{
-				styra: styra.StyraAPI{},
+				styra: styra.StyraAPI{
+					Policies: withMockLabelsPolicy(t, "kubernetes:v123"),
+				},
 				cr: System(
 					withExternalName(testSystemID),
 					withSpec(v1alpha1.SystemParameters{
@@ -1627,13 +2211,20 @@ func TestAreLabelsUpToDate(t *testing.T) {
 				nil,
 			},
 		},
-		"UnsupportedSystem": {
+		"OtherSystemType": {
 			args: args{
-				styra: styra.StyraAPI{},
+				styra: styra.StyraAPI{
+					Policies: withMockLabelsPolicy(t, "fooType"),
+				},
 				cr: System(
 					withExternalName(testSystemID),
 					withSpec(v1alpha1.SystemParameters{
 						Type: "fooType",
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Labels: map[string]string{
+								testLabelKey: testLabelValue,
+							},
+						},
 					}),
 				),
 			},
@@ -1646,7 +2237,7 @@ func TestAreLabelsUpToDate(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{client: &tc.styra}
+			e := &external{client: &tc.styra, recorder: event.NewNopRecorder()}
 			actlUpToDate, err := e.areLabelsUpToDate(context.Background(), tc.args.cr)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -1774,6 +2365,59 @@ func TestGetConnectionDetails(t *testing.T) {
 				nil,
 			},
 		},
+		"SelectedAssets": {
+			args: args{
+				styra: styra.StyraAPI{
+					Systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+						mcs.EXPECT().
+							GetAsset(&systems.GetAssetParams{
+								Assettype: "opa-config",
+								System:    testSystemID,
+								Context:   context.Background(),
+							}, &bytes.Buffer{}, gomock.Any()).
+							DoAndReturn(func(params *systems.GetAssetParams, writer io.Writer, _ ...systems.ClientOption) (*systems.GetAssetOK, error) {
+								writer.Write([]byte(testAsset))
+								return nil, nil
+							})
+						mcs.EXPECT().
+							GetInstructions(&systems.GetInstructionsParams{
+								System:  testSystemID,
+								Context: context.Background(),
+							}).
+							Return(&systems.GetInstructionsOK{
+								Payload: &models.SystemsV1SystemsGetInstructionsResponse{
+									Result: &models.SystemsV1DeploymentInstructions{},
+								},
+							}, nil)
+					}),
+				},
+				cr: System(
+					withExternalName(testSystemID),
+					withSpec(v1alpha1.SystemParameters{
+						CustomSystemParameters: v1alpha1.CustomSystemParameters{
+							Assets: []v1alpha1.SystemAsset{
+								{
+									Type:     v1alpha1.SystemAssetTypeOpaConfig,
+									Key:      styraclient.String("config"),
+									Encoding: styraclient.String(v1alpha1.SystemAssetEncodingBase64),
+								},
+								{
+									Type: v1alpha1.SystemAssetTypeInstallInstructions,
+								},
+							},
+						},
+						Type: "custom",
+					}),
+				),
+			},
+			want: want{
+				managed.ConnectionDetails{
+					"config":              []byte(base64.StdEncoding.EncodeToString([]byte(testAsset))),
+					"installInstructions": []byte(`{"install":null,"uninstall":null}`),
+				},
+				nil,
+			},
+		},
 		"ErrorGetAsset": {
 			args: args{
 				styra: styra.StyraAPI{
@@ -1803,7 +2447,7 @@ func TestGetConnectionDetails(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := &external{client: &tc.styra}
+			e := &external{client: &tc.styra, recorder: event.NewNopRecorder()}
 			actlConnDetails, err := e.getConnectionDetails(context.Background(), tc.args.cr)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -1815,3 +2459,384 @@ func TestGetConnectionDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestOpaConfigConnectionDetails(t *testing.T) {
+	type want struct {
+		sameHash bool
+		notAfter *time.Time
+		err      error
+	}
+
+	rand := mathrand.New(mathrand.NewSource(1))
+	key, err := rsa.GenerateKey(rand, 512)
+	if err != nil {
+		t.Error(err)
+	}
+	testCertExpire := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	testCert, err := x509.CreateCertificate(
+		rand,
+		&x509.Certificate{
+			NotAfter:     testCertExpire,
+			SerialNumber: big.NewInt(1),
+		},
+		&x509.Certificate{},
+		&key.PublicKey,
+		key,
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	testCertPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testCert}))
+
+	opaConfig := func(token, cert string) []byte {
+		b, _ := yaml.Marshal(map[string]interface{}{
+			"discovery": map[string]interface{}{"name": "discovery", "service": "styra"},
+			"services": []interface{}{
+				map[string]interface{}{
+					"name": "styra",
+					"url":  "https://tenant.styra.com/v1",
+					"credentials": map[string]interface{}{
+						"bearer":     map[string]interface{}{"token": token},
+						"client_tls": map[string]interface{}{"cert": cert, "private_key": "key-" + token},
+					},
+				},
+			},
+		})
+		return b
+	}
+
+	cases := map[string]struct {
+		a    []byte
+		b    []byte
+		want want
+	}{
+		"OnlyVolatileFieldsChanged": {
+			a:    opaConfig("token-a", testCertPem),
+			b:    opaConfig("token-b", base64.StdEncoding.EncodeToString([]byte(testCertPem))),
+			want: want{sameHash: true, notAfter: &testCertExpire},
+		},
+		"CertificateFilePath": {
+			a:    opaConfig("token-a", "/etc/opa/cert.pem"),
+			b:    opaConfig("token-a", "/etc/opa/cert.pem"),
+			want: want{sameHash: true},
+		},
+		"NotYAML": {
+			a:    []byte("{"),
+			b:    []byte("{{"),
+			want: want{sameHash: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(withSpec(v1alpha1.SystemParameters{Type: "custom"}))
+			_, hashA, err := shouldPublishConnectionDetails(cr, managed.ConnectionDetails{"opaConfig": tc.a})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			_, hashB, _ := shouldPublishConnectionDetails(cr, managed.ConnectionDetails{"opaConfig": tc.b})
+			if diff := cmp.Diff(tc.want.sameHash, hashA == hashB); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+
+			cert, err := getCertFromConnectionDetails(cr, managed.ConnectionDetails{"opaConfig": tc.a})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			var notAfter *time.Time
+			if cert != nil {
+				notAfter = &cert.NotAfter
+			}
+			if diff := cmp.Diff(tc.want.notAfter, notAfter); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTypeCondition(t *testing.T) {
+	cases := map[string]struct {
+		cr   *v1alpha1.System
+		want xpv1.Condition
+	}{
+		"KnownType": {
+			cr: System(
+				withSpec(v1alpha1.SystemParameters{
+					Type: kubernetesV2Type,
+					DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+						Namespace: styraclient.String("styra-system"),
+					},
+				}),
+			),
+			want: v1alpha1.SystemTypeKnown(),
+		},
+		"UnknownType": {
+			cr: System(
+				withSpec(v1alpha1.SystemParameters{
+					Type: "fooType",
+				}),
+			),
+			want: v1alpha1.SystemTypeUnknown("fooType"),
+		},
+		"CustomDeploymentParameters": {
+			cr: System(
+				withSpec(v1alpha1.SystemParameters{
+					Type: v1alpha1.SystemTypeCustom,
+					DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+						HTTPProxy: styraclient.String("http://proxy:3128"),
+					},
+				}),
+			),
+			want: v1alpha1.SystemTypeKnown(),
+		},
+		"UnsupportedDeploymentParameters": {
+			cr: System(
+				withSpec(v1alpha1.SystemParameters{
+					Type: v1alpha1.SystemTypeTerraform,
+					DeploymentParameters: &v1alpha1.V1SystemDeploymentParameters{
+						Namespace: styraclient.String("styra-system"),
+					},
+				}),
+			),
+			want: v1alpha1.UnsupportedParameters(v1alpha1.SystemTypeTerraform, "deploymentParameters"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, typeCondition(tc.cr), test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateDeploymentParameters(t *testing.T) {
+	params := &v1alpha1.V1SystemDeploymentParameters{
+		HTTPProxy: styraclient.String("http://proxy:3128"),
+	}
+
+	cases := map[string]struct {
+		cr   *v1alpha1.System
+		want *models.SystemsV1SystemDeploymentParameters
+	}{
+		"NotSet": {
+			cr:   System(withSpec(v1alpha1.SystemParameters{Type: kubernetesV2Type})),
+			want: nil,
+		},
+		"Kubernetes": {
+			cr: System(withSpec(v1alpha1.SystemParameters{
+				Type:                 kubernetesV2Type,
+				DeploymentParameters: params,
+			})),
+			want: &models.SystemsV1SystemDeploymentParameters{HTTPProxy: "http://proxy:3128"},
+		},
+		// Deployment parameters of custom systems have been sent before the
+		// system type registry was introduced and must not be dropped.
+		"Custom": {
+			cr: System(withSpec(v1alpha1.SystemParameters{
+				Type:                 v1alpha1.SystemTypeCustom,
+				DeploymentParameters: params,
+			})),
+			want: &models.SystemsV1SystemDeploymentParameters{HTTPProxy: "http://proxy:3128"},
+		},
+		"Unsupported": {
+			cr: System(withSpec(v1alpha1.SystemParameters{
+				Type:                 v1alpha1.SystemTypeTerraform,
+				DeploymentParameters: params,
+			})),
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, generateDeploymentParameters(tc.cr)); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDatasourcesCondition(t *testing.T) {
+	failed := &v1alpha1.V1Status{Code: "failed", Message: "cannot clone repository"}
+	finished := &v1alpha1.V1Status{Code: "finished"}
+
+	cases := map[string]struct {
+		datasources []*v1alpha1.V1DatasourceConfig
+		want        xpv1.Condition
+	}{
+		"NoDatasources": {
+			want: v1alpha1.DatasourcesHealthy(),
+		},
+		"Healthy": {
+			datasources: []*v1alpha1.V1DatasourceConfig{
+				{ID: "systems/testsystem/kubernetes/resources", Status: finished},
+				{ID: "systems/testsystem/git", Status: nil},
+			},
+			want: v1alpha1.DatasourcesHealthy(),
+		},
+		"OptionalFailing": {
+			datasources: []*v1alpha1.V1DatasourceConfig{
+				{ID: "systems/testsystem/git", Optional: true, Status: failed},
+			},
+			want: v1alpha1.DatasourcesHealthy(),
+		},
+		"RequiredFailing": {
+			datasources: []*v1alpha1.V1DatasourceConfig{
+				{ID: "systems/testsystem/kubernetes/resources", Status: failed},
+				{ID: "systems/testsystem/git", Optional: true, Status: failed},
+				{ID: "systems/testsystem/http", Status: failed},
+			},
+			want: v1alpha1.DatasourcesFailing([]string{"systems/testsystem/kubernetes/resources", "systems/testsystem/http"}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, datasourcesCondition(tc.datasources), test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFindExisting(t *testing.T) {
+	otherName := "other"
+	otherID := "othersystem"
+
+	type want struct {
+		id  string
+		cr  *v1alpha1.System
+		err error
+	}
+
+	listSystems := func(result []*models.SystemsV1SystemConfig, err error) *mocksystem.MockClientService {
+		return withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+			mcs.EXPECT().
+				ListSystems(&systems.ListSystemsParams{
+					Context: context.Background(),
+					Type:    &testType,
+				}).
+				Return(&systems.ListSystemsOK{
+					Payload: &models.SystemsV1SystemsListResponse{Result: result},
+				}, err)
+		})
+	}
+
+	spec := v1alpha1.SystemParameters{
+		CustomSystemParameters: v1alpha1.CustomSystemParameters{
+			AdoptExisting: true,
+		},
+		ExternalID: &testExternalID,
+		Type:       testType,
+	}
+
+	cases := map[string]struct {
+		systems *mocksystem.MockClientService
+		want
+	}{
+		"NoMatch": {
+			systems: listSystems([]*models.SystemsV1SystemConfig{
+				{ID: &otherID, Name: &otherName},
+			}, nil),
+			want: want{
+				cr: System(withName(testSystemName), withSpec(spec)),
+			},
+		},
+		"MatchByName": {
+			systems: listSystems([]*models.SystemsV1SystemConfig{
+				{ID: &otherID, Name: &otherName},
+				{ID: &testSystemID, Name: &testSystemName},
+			}, nil),
+			want: want{
+				id: testSystemID,
+				cr: System(withName(testSystemName), withSpec(spec)),
+			},
+		},
+		"MatchByExternalID": {
+			systems: listSystems([]*models.SystemsV1SystemConfig{
+				{ID: &testSystemID, Name: &otherName, ExternalID: testExternalID},
+			}, nil),
+			want: want{
+				id: testSystemID,
+				cr: System(withName(testSystemName), withSpec(spec)),
+			},
+		},
+		"Ambiguous": {
+			systems: listSystems([]*models.SystemsV1SystemConfig{
+				{ID: &testSystemID, Name: &testSystemName},
+				{ID: &otherID, Name: &otherName, ExternalID: testExternalID},
+			}, nil),
+			want: want{
+				cr: System(
+					withName(testSystemName),
+					withSpec(spec),
+					withConditions(v1alpha1.AdoptionAmbiguous([]string{testSystemID, otherID})),
+				),
+				err: errors.Errorf(errAmbiguousSystems, 2),
+			},
+		},
+		"ListSystemsFailed": {
+			systems: listSystems(nil, errBoom),
+			want: want{
+				cr:  System(withName(testSystemName), withSpec(spec)),
+				err: errors.Wrap(errBoom, errListSystems),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(withName(testSystemName), withSpec(spec))
+			e := &external{client: &styra.StyraAPI{Systems: tc.systems}, recorder: event.NewNopRecorder()}
+			id, err := e.findExisting(context.Background(), cr)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, cr, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsLabelsRejected(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		want bool
+	}{
+		"Invalid": {
+			err:  errors.Wrap(&styraclient.APIError{StatusCode: 400}, errUpdateLabels),
+			want: true,
+		},
+		"Unauthorized": {
+			err:  errors.Wrap(&styraclient.APIError{StatusCode: 401}, errUpdateLabels),
+			want: false,
+		},
+		"Forbidden": {
+			err:  errors.Wrap(&styraclient.APIError{StatusCode: 403}, errUpdateLabels),
+			want: false,
+		},
+		"NotFound": {
+			err:  errors.Wrap(&styraclient.APIError{StatusCode: 404}, errUpdateLabels),
+			want: false,
+		},
+		"NotAnAPIError": {
+			err:  errBoom,
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, isLabelsRejected(tc.err)); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package system
 
 import (
+	"encoding/json"
 	"errors"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/mistermx/styra-go-client/pkg/client/systems"
 	"github.com/mistermx/styra-go-client/pkg/models"
@@ -22,17 +27,14 @@ func generateSystem(resp *models.SystemsV1SystemConfig) (cr *v1alpha1.System) {
 			n.Optional = v.Optional
 			n.Category = styraclient.StringValue(v.Category)
 			n.Type = styraclient.StringValue(v.Type)
-			if v.Status != nil {
-				n.Status = &v1alpha1.V1Status{
-					// AuthzMigration: v.Status.AuthzMigration,
-				}
-			}
+			n.Status = generateDatasourceStatus(v.Status)
 			cr.Status.AtProvider.Datasources[i] = n
 		}
 	}
 	if resp.DeploymentParameters != nil {
 		cr.Spec.ForProvider.DeploymentParameters = &v1alpha1.V1SystemDeploymentParameters{
 			DenyOnOpaFail:            resp.DeploymentParameters.DenyOnOpaFail,
+			Extra:                    generateExtra(resp.DeploymentParameters.Extra),
 			HTTPProxy:                styraclient.String(resp.DeploymentParameters.HTTPProxy),
 			HTTPSProxy:               styraclient.String(resp.DeploymentParameters.HTTPSProxy),
 			KubernetesVersion:        styraclient.String(resp.DeploymentParameters.KubernetesVersion),
@@ -46,6 +48,7 @@ func generateSystem(resp *models.SystemsV1SystemConfig) (cr *v1alpha1.System) {
 	cr.Spec.ForProvider.Description = &resp.Description
 	cr.Spec.ForProvider.ExternalID = &resp.ExternalID
 	cr.Spec.ForProvider.ReadOnly = resp.ReadOnly
+	cr.Spec.ForProvider.SourceControl = generateSourceControlConfig(resp.SourceControl)
 	cr.Spec.ForProvider.Type = styraclient.StringValue(resp.Type)
 
 	return cr
@@ -54,7 +57,7 @@ func generateSystem(resp *models.SystemsV1SystemConfig) (cr *v1alpha1.System) {
 // generateSystemPostRequest generates models.SystemsV1SystemsPostRequest from v1alpha1.System
 func generateSystemPostRequest(cr *v1alpha1.System) *models.SystemsV1SystemsPostRequest {
 	return &models.SystemsV1SystemsPostRequest{
-		DeploymentParameters: generateDeploymentParameters(cr.Spec.ForProvider.DeploymentParameters),
+		DeploymentParameters: generateDeploymentParameters(cr),
 		Description:          styraclient.StringValue(cr.Spec.ForProvider.Description),
 		ExternalID:           styraclient.StringValue(cr.Spec.ForProvider.ExternalID),
 		Name:                 styraclient.String(cr.ObjectMeta.Name),
@@ -66,19 +69,22 @@ func generateSystemPostRequest(cr *v1alpha1.System) *models.SystemsV1SystemsPost
 // generateSystemPutRequest generates models.SystemsV1SystemsPutRequest from v1alpha1.System
 func generateSystemPutRequest(cr *v1alpha1.System) *models.SystemsV1SystemsPutRequest {
 	return &models.SystemsV1SystemsPutRequest{
-		DeploymentParameters: generateDeploymentParameters(cr.Spec.ForProvider.DeploymentParameters),
+		DeploymentParameters: generateDeploymentParameters(cr),
 		Description:          styraclient.StringValue(cr.Spec.ForProvider.Description),
 		ExternalID:           styraclient.StringValue(cr.Spec.ForProvider.ExternalID),
 		Name:                 styraclient.String(cr.ObjectMeta.Name),
 		ReadOnly:             cr.Spec.ForProvider.ReadOnly,
+		SourceControl:        generateModelSourceControlConfig(cr.Spec.ForProvider.SourceControl),
 		Type:                 styraclient.String(cr.Spec.ForProvider.Type),
 	}
 }
 
-func generateDeploymentParameters(spec *v1alpha1.V1SystemDeploymentParameters) *models.SystemsV1SystemDeploymentParameters {
-	if spec != nil {
+func generateDeploymentParameters(cr *v1alpha1.System) *models.SystemsV1SystemDeploymentParameters {
+	spec := cr.Spec.ForProvider.DeploymentParameters
+	if spec != nil && cr.Spec.ForProvider.GetTypeInfo().DeploymentParameters {
 		return &models.SystemsV1SystemDeploymentParameters{
 			DenyOnOpaFail:            spec.DenyOnOpaFail,
+			Extra:                    generateModelExtra(spec.Extra),
 			HTTPProxy:                styraclient.StringValue(spec.HTTPProxy),
 			HTTPSProxy:               styraclient.StringValue(spec.HTTPSProxy),
 			KubernetesVersion:        styraclient.StringValue(spec.KubernetesVersion),
@@ -102,6 +108,9 @@ func lateInitializeDeploymentParameters(spec *v1alpha1.V1SystemDeploymentParamet
 	}
 
 	spec.DenyOnOpaFail = styraclient.LateInitializeBoolPtr(spec.DenyOnOpaFail, current.DenyOnOpaFail)
+	if spec.Extra == nil {
+		spec.Extra = current.Extra
+	}
 	spec.HTTPProxy = styraclient.LateInitializeStringPtr(spec.HTTPProxy, current.HTTPProxy)
 	spec.HTTPSProxy = styraclient.LateInitializeStringPtr(spec.HTTPSProxy, current.HTTPSProxy)
 	spec.KubernetesVersion = styraclient.LateInitializeStringPtr(spec.KubernetesVersion, current.KubernetesVersion)
@@ -115,8 +124,80 @@ func lateInitializeDeploymentParameters(spec *v1alpha1.V1SystemDeploymentParamet
 	return spec
 }
 
-// isNotFound returns whether the given error is of type NotFound or not.
+func generateSourceControlConfig(current *models.GitV1SourceControlConfig) *v1alpha1.V1SourceControlConfig {
+	if current == nil || current.Origin == nil {
+		return nil
+	}
+	return &v1alpha1.V1SourceControlConfig{
+		Origin: v1alpha1.V1GitRepoConfig{
+			Credentials: styraclient.StringValue(current.Origin.Credentials),
+			Path:        styraclient.StringValue(current.Origin.Path),
+			Reference:   styraclient.StringValue(current.Origin.Reference),
+			URL:         styraclient.StringValue(current.Origin.URL),
+		},
+	}
+}
+
+func generateModelSourceControlConfig(spec *v1alpha1.V1SourceControlConfig) *models.GitV1SourceControlConfig {
+	if spec == nil {
+		return nil
+	}
+	return &models.GitV1SourceControlConfig{
+		Origin: &models.GitV1GitRepoConfig{
+			Credentials: styraclient.String(spec.Origin.Credentials),
+			Path:        styraclient.String(spec.Origin.Path),
+			Reference:   styraclient.String(spec.Origin.Reference),
+			URL:         styraclient.String(spec.Origin.URL),
+		},
+	}
+}
+
+func lateInitializeSourceControlConfig(spec, current *v1alpha1.V1SourceControlConfig) *v1alpha1.V1SourceControlConfig {
+	if spec != nil {
+		return spec
+	}
+	return current
+}
+
+func generateDatasourceStatus(status *models.MetaV1Status) *v1alpha1.V1Status {
+	if status == nil {
+		return nil
+	}
+	s := &v1alpha1.V1Status{
+		Code:    styraclient.StringValue(status.Code),
+		Message: styraclient.StringValue(status.Message),
+	}
+	if status.Timestamp != nil && !time.Time(*status.Timestamp).IsZero() {
+		s.Timestamp = &metav1.Time{Time: time.Time(*status.Timestamp)}
+	}
+	return s
+}
+
+// generateExtra generates the extra deployment parameters from the decoded
+// JSON returned by the Styra API.
+func generateExtra(current interface{}) *runtime.RawExtension {
+	if current == nil {
+		return nil
+	}
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return nil
+	}
+	return &runtime.RawExtension{Raw: raw}
+}
+
+// generateModelExtra generates the extra deployment parameters that are sent
+// to the Styra API. They are sent as is.
+func generateModelExtra(spec *runtime.RawExtension) interface{} {
+	if spec == nil || len(spec.Raw) == 0 {
+		return nil
+	}
+	return json.RawMessage(spec.Raw)
+}
+
+// isNotFound returns whether the given error is of type NotFound or any
+// other Styra API error with status 404.
 func isNotFound(err error) bool {
 	var snf *systems.GetSystemNotFound
-	return errors.As(err, &snf)
+	return errors.As(err, &snf) || styraclient.IsNotFound(err)
 }
@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/mistermx/styra-go-client/pkg/client/policies"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+)
+
+const (
+	errGetPolicy          = "cannot get policy %s"
+	errUpdatePolicy       = "cannot update policy %s"
+	errGetModuleConfigMap = "cannot get config map of module %s of policy %s"
+	errMissingModuleKey   = "config map %s/%s has no key %s"
+	errNoModuleSource     = "module %s of policy %s has neither rego nor configMapKeyRef"
+)
+
+// arePoliciesUpToDate returns whether the modules of all policies of cr match
+// the ones in Styra.
+func (e *external) arePoliciesUpToDate(ctx context.Context, cr *v1alpha1.System) (bool, error) {
+	desired, err := e.resolvePolicies(ctx, cr)
+	if err != nil {
+		return false, err
+	}
+
+	for _, path := range sortedPolicyPaths(desired) {
+		policy := systemPolicyPath(cr, path)
+		resp, err := e.client.Policies.GetPolicy(&policies.GetPolicyParams{
+			Context: ctx,
+			Policy:  policy,
+		})
+		if styraclient.IsPolicyNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrapf(err, errGetPolicy, policy)
+		}
+		current, ok := styraclient.GetPolicyModules(resp)
+		if !ok || !isEqualModules(desired[path], current) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// updatePolicies writes all policies of cr to Styra.
+func (e *external) updatePolicies(ctx context.Context, cr *v1alpha1.System) error {
+	desired, err := e.resolvePolicies(ctx, cr)
+	if err != nil {
+		return err
+	}
+
+	// Policies are written one by one instead of with a bulk upload so that
+	// policies of the system that are not managed by cr are kept.
+	for _, path := range sortedPolicyPaths(desired) {
+		policy := systemPolicyPath(cr, path)
+		_, err := e.client.Policies.UpdatePolicy(&policies.UpdatePolicyParams{
+			Context: ctx,
+			Policy:  policy,
+			Body: &models.PoliciesV1PoliciesPutRequest{
+				Modules: desired[path],
+			},
+		})
+		if err != nil {
+			return errors.Wrapf(err, errUpdatePolicy, policy)
+		}
+	}
+	return nil
+}
+
+// resolvePolicies returns the rego of the modules of all policies of cr by
+// their path and module name.
+func (e *external) resolvePolicies(ctx context.Context, cr *v1alpha1.System) (map[string]map[string]string, error) {
+	resolved := make(map[string]map[string]string, len(cr.Spec.ForProvider.Policies))
+	for path, p := range cr.Spec.ForProvider.Policies {
+		modules := make(map[string]string, len(p.Modules))
+		for name, m := range p.Modules {
+			rego, err := e.resolveModule(ctx, path, name, m)
+			if err != nil {
+				return nil, err
+			}
+			modules[name] = rego
+		}
+		resolved[path] = modules
+	}
+	return resolved, nil
+}
+
+func (e *external) resolveModule(ctx context.Context, path, name string, m v1alpha1.SystemPolicyModule) (string, error) {
+	if m.Rego != nil {
+		return *m.Rego, nil
+	}
+	ref := m.ConfigMapKeyRef
+	if ref == nil {
+		return "", errors.Errorf(errNoModuleSource, name, path)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := e.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return "", errors.Wrapf(err, errGetModuleConfigMap, name, path)
+	}
+	rego, ok := cm.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf(errMissingModuleKey, ref.Namespace, ref.Name, ref.Key)
+	}
+	return rego, nil
+}
+
+// isEqualModules returns whether the given modules are semantically equal.
+// Modules are compared after parsing them so that differences in formatting
+// and comments are ignored. Modules that cannot be parsed are compared as
+// is.
+func isEqualModules(desired, current map[string]string) bool {
+	if len(desired) != len(current) {
+		return false
+	}
+	for name, d := range desired {
+		c, ok := current[name]
+		if !ok || !isEqualModule(name, d, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEqualModule(name, desired, current string) bool {
+	d, err := ast.ParseModule(name, desired)
+	if err != nil {
+		return desired == current
+	}
+	c, err := ast.ParseModule(name, current)
+	if err != nil {
+		return false
+	}
+	return d.Equal(c)
+}
+
+// systemPolicyPath returns the full path of the policy with the given path
+// relative to the system of cr.
+func systemPolicyPath(cr *v1alpha1.System, path string) string {
+	return fmt.Sprintf("systems/%s/%s", meta.GetExternalName(cr), strings.Trim(path, "/"))
+}
+
+func sortedPolicyPaths(p map[string]map[string]string) []string {
+	paths := make([]string, 0, len(p))
+	for path := range p {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
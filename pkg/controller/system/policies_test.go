@@ -0,0 +1,270 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/client/policies"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	providerv1alpha1 "github.com/crossplane-contrib/provider-styra/apis/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+	mockpolicies "github.com/crossplane-contrib/provider-styra/pkg/client/mock/policies"
+)
+
+const (
+	testRulesPath = "policy/com.styra.kubernetes.validating/rules/rules"
+	testRulesRego = `package policy["com.styra.kubernetes.validating"].rules.rules
+
+enforce[decision] {
+	false
+	decision := {"allowed": false, "message": "never"}
+}
+`
+	// testRulesRegoReformatted equals testRulesRego except for formatting
+	// and comments.
+	testRulesRegoReformatted = `package policy["com.styra.kubernetes.validating"].rules.rules
+
+# Never deny anything.
+enforce[decision] { false; decision := {"allowed": false, "message": "never"} }
+`
+)
+
+func TestIsEqualModules(t *testing.T) {
+	cases := map[string]struct {
+		desired map[string]string
+		current map[string]string
+		want    bool
+	}{
+		"Equal": {
+			desired: map[string]string{"rules.rego": testRulesRego},
+			current: map[string]string{"rules.rego": testRulesRego},
+			want:    true,
+		},
+		"FormattingDiffers": {
+			desired: map[string]string{"rules.rego": testRulesRego},
+			current: map[string]string{"rules.rego": testRulesRegoReformatted},
+			want:    true,
+		},
+		"RuleDiffers": {
+			desired: map[string]string{"rules.rego": testRulesRego},
+			current: map[string]string{"rules.rego": "package policy[\"com.styra.kubernetes.validating\"].rules.rules\n"},
+			want:    false,
+		},
+		"ModuleMissing": {
+			desired: map[string]string{"rules.rego": testRulesRego, "test.rego": testRulesRego},
+			current: map[string]string{"rules.rego": testRulesRego},
+			want:    false,
+		},
+		"UnparsableEqual": {
+			desired: map[string]string{"rules.rego": "not rego"},
+			current: map[string]string{"rules.rego": "not rego"},
+			want:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := isEqualModules(tc.desired, tc.current)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolvePolicies(t *testing.T) {
+	ref := &providerv1alpha1.ConfigMapKeySelector{Name: "rules", Namespace: "default", Key: "rules.rego"}
+
+	type want struct {
+		policies map[string]map[string]string
+		err      error
+	}
+
+	cases := map[string]struct {
+		policies map[string]v1alpha1.SystemPolicy
+		kube     client.Client
+		want     want
+	}{
+		"Inline": {
+			policies: map[string]v1alpha1.SystemPolicy{
+				testRulesPath: {Modules: map[string]v1alpha1.SystemPolicyModule{
+					"rules.rego": {Rego: styraclient.String(testRulesRego)},
+				}},
+			},
+			want: want{
+				policies: map[string]map[string]string{
+					testRulesPath: {"rules.rego": testRulesRego},
+				},
+			},
+		},
+		"ConfigMap": {
+			policies: map[string]v1alpha1.SystemPolicy{
+				testRulesPath: {Modules: map[string]v1alpha1.SystemPolicyModule{
+					"rules.rego": {ConfigMapKeyRef: ref},
+				}},
+			},
+			kube: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*corev1.ConfigMap).Data = map[string]string{"rules.rego": testRulesRego}
+					return nil
+				}),
+			},
+			want: want{
+				policies: map[string]map[string]string{
+					testRulesPath: {"rules.rego": testRulesRego},
+				},
+			},
+		},
+		"ConfigMapKeyMissing": {
+			policies: map[string]v1alpha1.SystemPolicy{
+				testRulesPath: {Modules: map[string]v1alpha1.SystemPolicyModule{
+					"rules.rego": {ConfigMapKeyRef: ref},
+				}},
+			},
+			kube: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil),
+			},
+			want: want{
+				err: errors.Errorf(errMissingModuleKey, "default", "rules", "rules.rego"),
+			},
+		},
+		"NoSource": {
+			policies: map[string]v1alpha1.SystemPolicy{
+				testRulesPath: {Modules: map[string]v1alpha1.SystemPolicyModule{
+					"rules.rego": {},
+				}},
+			},
+			want: want{
+				err: errors.Errorf(errNoModuleSource, "rules.rego", testRulesPath),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(withSpec(v1alpha1.SystemParameters{
+				CustomSystemParameters: v1alpha1.CustomSystemParameters{
+					Policies: tc.policies,
+				},
+			}))
+			e := &external{kube: tc.kube, recorder: event.NewNopRecorder()}
+			got, err := e.resolvePolicies(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.policies, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestArePoliciesUpToDate(t *testing.T) {
+	policy := "systems/" + testSystemID + "/" + testRulesPath
+	withRules := func(rego string) *mockpolicies.MockClientService {
+		return withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+			mcs.EXPECT().
+				GetPolicy(&policies.GetPolicyParams{
+					Context: context.Background(),
+					Policy:  policy,
+				}).
+				Return(&policies.GetPolicyOK{
+					Payload: &models.PoliciesV1PolicyGetResponse{
+						Result: map[string]interface{}{
+							"modules": map[string]interface{}{
+								"rules.rego": rego,
+							},
+						},
+					},
+				}, nil)
+		})
+	}
+
+	type want struct {
+		upToDate bool
+		err      error
+	}
+
+	cases := map[string]struct {
+		policies *mockpolicies.MockClientService
+		want     want
+	}{
+		"UpToDate": {
+			policies: withRules(testRulesRegoReformatted),
+			want:     want{upToDate: true},
+		},
+		"Drift": {
+			policies: withRules("package policy[\"com.styra.kubernetes.validating\"].rules.rules\n"),
+			want:     want{upToDate: false},
+		},
+		"PolicyNotFound": {
+			policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+				mcs.EXPECT().
+					GetPolicy(gomock.Any()).
+					Return(nil, &policies.GetPolicyNotFound{})
+			}),
+			want: want{upToDate: false},
+		},
+		"GetPolicyFailed": {
+			policies: withMockPolicies(t, func(mcs *mockpolicies.MockClientService) {
+				mcs.EXPECT().
+					GetPolicy(gomock.Any()).
+					Return(nil, errBoom)
+			}),
+			want: want{err: errors.Wrapf(errBoom, errGetPolicy, policy)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(
+				withExternalName(testSystemID),
+				withSpec(v1alpha1.SystemParameters{
+					CustomSystemParameters: v1alpha1.CustomSystemParameters{
+						Policies: map[string]v1alpha1.SystemPolicy{
+							testRulesPath: {Modules: map[string]v1alpha1.SystemPolicyModule{
+								"rules.rego": {Rego: styraclient.String(testRulesRego)},
+							}},
+						},
+					},
+				}),
+			)
+			e := &external{client: &styra.StyraAPI{Policies: tc.policies}, recorder: event.NewNopRecorder()}
+			got, err := e.arePoliciesUpToDate(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
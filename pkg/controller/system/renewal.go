@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+)
+
+// renewalReconciler requeues a System at the time its connection details
+// must be renewed if that is earlier than the requeue of the wrapped
+// reconciler.
+type renewalReconciler struct {
+	reconcile.Reconciler
+	kube client.Client
+}
+
+// Reconcile the System using the wrapped reconciler and requeue it for
+// renewal of its connection details.
+func (r *renewalReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	if err != nil || result.Requeue && result.RequeueAfter == 0 {
+		return result, err
+	}
+
+	cr := &v1alpha1.System{}
+	if err := r.kube.Get(ctx, req.NamespacedName, cr); err != nil {
+		// The System might have been deleted. Keep the result of the wrapped
+		// reconciler in any case.
+		return result, nil //nolint:nilerr
+	}
+
+	return requeueForRenewal(result, cr.GetConnectionDetailsRenewalTime(), time.Now()), nil
+}
+
+// requeueForRenewal shortens the requeue of result to the renewal time if it
+// is in the future.
+func requeueForRenewal(result reconcile.Result, renewal, now time.Time) reconcile.Result {
+	if renewal.IsZero() || !renewal.After(now) {
+		return result
+	}
+	until := renewal.Sub(now)
+	if result.RequeueAfter == 0 || until < result.RequeueAfter {
+		result.RequeueAfter = until
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRequeueForRenewal(t *testing.T) {
+	now := time.Now()
+
+	cases := map[string]struct {
+		result  reconcile.Result
+		renewal time.Time
+		want    reconcile.Result
+	}{
+		"NoCertificate": {
+			result: reconcile.Result{RequeueAfter: time.Minute},
+			want:   reconcile.Result{RequeueAfter: time.Minute},
+		},
+		"RenewalBeforePoll": {
+			result:  reconcile.Result{RequeueAfter: time.Minute},
+			renewal: now.Add(10 * time.Second),
+			want:    reconcile.Result{RequeueAfter: 10 * time.Second},
+		},
+		"RenewalAfterPoll": {
+			result:  reconcile.Result{RequeueAfter: time.Minute},
+			renewal: now.Add(time.Hour),
+			want:    reconcile.Result{RequeueAfter: time.Minute},
+		},
+		"RenewalPassed": {
+			result:  reconcile.Result{RequeueAfter: time.Minute},
+			renewal: now.Add(-time.Hour),
+			want:    reconcile.Result{RequeueAfter: time.Minute},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := requeueForRenewal(tc.result, tc.renewal, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
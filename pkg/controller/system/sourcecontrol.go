@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // Not used for security
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+)
+
+const (
+	errVerifySourceControl = "cannot verify source control config"
+)
+
+// observeSourceControl lets Styra verify the source control config of cr if
+// it has changed since it was last verified and sets the
+// SourceControlVerified condition accordingly. Failing to verify the config
+// does not fail the observation of the system itself.
+func (e *external) observeSourceControl(ctx context.Context, cr *v1alpha1.System) {
+	spec := cr.Spec.ForProvider.SourceControl
+	if spec == nil {
+		cr.Status.AtProvider.SourceControlHash = ""
+		removeCondition(cr, v1alpha1.TypeSourceControlVerified)
+		return
+	}
+
+	hash := hashSourceControl(spec)
+	if hash == cr.Status.AtProvider.SourceControlHash {
+		return
+	}
+
+	resp, err := e.client.Systems.SourceControlVerifyConfigSystem(&systems.SourceControlVerifyConfigSystemParams{
+		Context: ctx,
+		Body: &models.GitV1VerifyConfigRequest{
+			Credentials: styraclient.String(spec.Origin.Credentials),
+			ID:          styraclient.String(meta.GetExternalName(cr)),
+			Path:        styraclient.String(spec.Origin.Path),
+			Reference:   styraclient.String(spec.Origin.Reference),
+			URL:         styraclient.String(spec.Origin.URL),
+		},
+	})
+	if err != nil {
+		cr.SetConditions(v1alpha1.SourceControlVerifyFailed(errors.Wrap(err, errVerifySourceControl)))
+		if styraclient.ReasonFor(err) == styraclient.ErrorReasonInvalid {
+			// Verifying the same config again would fail again. Other
+			// errors are retried on the next poll.
+			cr.Status.AtProvider.SourceControlHash = hash
+		}
+		return
+	}
+
+	sha := ""
+	if resp.Payload != nil && resp.Payload.Result != nil {
+		sha = styraclient.StringValue(resp.Payload.Result.Sha)
+	}
+	cr.SetConditions(v1alpha1.SourceControlVerified(sha))
+	cr.Status.AtProvider.SourceControlHash = hash
+}
+
+// hashSourceControl returns a hash of the given source control config.
+func hashSourceControl(spec *v1alpha1.V1SourceControlConfig) string {
+	raw, _ := json.Marshal(spec) //nolint:errchkjson // Cannot fail for a struct of strings.
+	sum := sha1.Sum(raw)         //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
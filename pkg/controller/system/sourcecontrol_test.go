@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+	mocksystem "github.com/crossplane-contrib/provider-styra/pkg/client/mock/systems"
+)
+
+func TestObserveSourceControl(t *testing.T) {
+	sha := "d5e9f2"
+	spec := &v1alpha1.V1SourceControlConfig{
+		Origin: v1alpha1.V1GitRepoConfig{
+			Credentials: "git-creds",
+			Path:        "policies",
+			Reference:   "refs/heads/main",
+			URL:         "https://github.com/example/policies.git",
+		},
+	}
+	hash := hashSourceControl(spec)
+	errRejected := &styraclient.APIError{Operation: "SourceControlVerifyConfigSystem", StatusCode: 400}
+
+	type want struct {
+		hash       string
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		spec       *v1alpha1.V1SourceControlConfig
+		hash       string
+		conditions []xpv1.Condition
+		systems    *mocksystem.MockClientService
+		want       want
+	}{
+		"NotSet": {
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+		},
+		"Removed": {
+			hash:       hash,
+			conditions: []xpv1.Condition{v1alpha1.SourceControlVerified(sha), v1alpha1.NoAgents()},
+			systems:    withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want: want{
+				conditions: []xpv1.Condition{v1alpha1.NoAgents()},
+			},
+		},
+		"Verified": {
+			spec: spec,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					SourceControlVerifyConfigSystem(&systems.SourceControlVerifyConfigSystemParams{
+						Context: context.Background(),
+						Body: &models.GitV1VerifyConfigRequest{
+							Credentials: styraclient.String("git-creds"),
+							ID:          styraclient.String(testSystemID),
+							Path:        styraclient.String("policies"),
+							Reference:   styraclient.String("refs/heads/main"),
+							URL:         styraclient.String("https://github.com/example/policies.git"),
+						},
+					}).
+					Return(&systems.SourceControlVerifyConfigSystemOK{
+						Payload: &models.GitV1VerifyConfigResponse{
+							Result: &models.GitV1VerifiedRepoConfig{Sha: &sha},
+						},
+					}, nil)
+			}),
+			want: want{
+				hash:       hash,
+				conditions: []xpv1.Condition{v1alpha1.SourceControlVerified(sha)},
+			},
+		},
+		"AlreadyVerified": {
+			spec:       spec,
+			hash:       hash,
+			conditions: []xpv1.Condition{v1alpha1.SourceControlVerified(sha)},
+			systems:    withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want: want{
+				hash:       hash,
+				conditions: []xpv1.Condition{v1alpha1.SourceControlVerified(sha)},
+			},
+		},
+		"ChangedSinceVerified": {
+			spec:       spec,
+			hash:       "outdated",
+			conditions: []xpv1.Condition{v1alpha1.SourceControlVerified("a1b2c3")},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					SourceControlVerifyConfigSystem(gomock.Any()).
+					Return(&systems.SourceControlVerifyConfigSystemOK{
+						Payload: &models.GitV1VerifyConfigResponse{
+							Result: &models.GitV1VerifiedRepoConfig{Sha: &sha},
+						},
+					}, nil)
+			}),
+			want: want{
+				hash:       hash,
+				conditions: []xpv1.Condition{v1alpha1.SourceControlVerified(sha)},
+			},
+		},
+		"VerifyRejected": {
+			spec: spec,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					SourceControlVerifyConfigSystem(gomock.Any()).
+					Return(nil, errRejected)
+			}),
+			want: want{
+				hash:       hash,
+				conditions: []xpv1.Condition{v1alpha1.SourceControlVerifyFailed(errors.Wrap(errRejected, errVerifySourceControl))},
+			},
+		},
+		"VerifyFailed": {
+			spec: spec,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					SourceControlVerifyConfigSystem(gomock.Any()).
+					Return(nil, errBoom)
+			}),
+			want: want{
+				conditions: []xpv1.Condition{v1alpha1.SourceControlVerifyFailed(errors.Wrap(errBoom, errVerifySourceControl))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(
+				withExternalName(testSystemID),
+				withSpec(v1alpha1.SystemParameters{SourceControl: tc.spec}),
+				withConditions(tc.conditions...),
+			)
+			cr.Status.AtProvider.SourceControlHash = tc.hash
+			e := &external{client: &styra.StyraAPI{Systems: tc.systems}, recorder: event.NewNopRecorder()}
+			e.observeSourceControl(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.hash, cr.Status.AtProvider.SourceControlHash); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, cr.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+)
+
+const (
+	// defaultValidationInterval is the interval between two validations of
+	// the same bundle if none is configured.
+	defaultValidationInterval = 10 * time.Minute
+
+	// validationModeAll reports all test results and violations instead of
+	// only the ones that changed.
+	validationModeAll = "all"
+
+	errValidateTests      = "cannot run policy tests"
+	errValidateCompliance = "cannot run compliance checks"
+)
+
+// observeValidation runs the policy tests and compliance checks of cr if they
+// are due and sets the PoliciesValid condition. The result of the last
+// validation is kept otherwise. The result and the condition are removed if
+// validation is disabled. Failing to validate the policies does not fail the
+// observation of the system itself.
+func (e *external) observeValidation(ctx context.Context, cr *v1alpha1.System, last *v1alpha1.SystemValidationObservation) {
+	spec := cr.Spec.ForProvider.Validation
+	if spec == nil || !spec.Enabled {
+		cr.Status.AtProvider.Validation = nil
+		removeCondition(cr, v1alpha1.TypePoliciesValid)
+		return
+	}
+
+	bundleID := ""
+	if cr.Status.AtProvider.Bundle != nil {
+		bundleID = cr.Status.AtProvider.Bundle.ID
+	}
+	now := time.Now()
+	if !isValidationDue(spec, last, bundleID, now) {
+		cr.Status.AtProvider.Validation = last
+		return
+	}
+
+	obs, err := e.validate(ctx, cr, last)
+	if err != nil {
+		cr.Status.AtProvider.Validation = last
+		cr.SetConditions(v1alpha1.ValidationUnknown(err))
+		return
+	}
+	obs.BundleID = bundleID
+	obs.LastValidatedAt = &metav1.Time{Time: now}
+	cr.Status.AtProvider.Validation = obs
+	cr.SetConditions(validationCondition(obs))
+}
+
+// validate runs the policy tests and compliance checks of cr. The number of
+// violations of the last validation is kept if the compliance checks have
+// not completed yet.
+func (e *external) validate(ctx context.Context, cr *v1alpha1.System, last *v1alpha1.SystemValidationObservation) (*v1alpha1.SystemValidationObservation, error) {
+	tests, err := e.client.Systems.ValidateSystemTests(&systems.ValidateSystemTestsParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+		Body: &models.SystemsV1SystemsTestsRequest{
+			Mode: styraclient.String(validationModeAll),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errValidateTests)
+	}
+	compliance, _, err := e.client.Systems.ValidateSystemCompliance(&systems.ValidateSystemComplianceParams{
+		Context: ctx,
+		System:  meta.GetExternalName(cr),
+		Body: &models.SystemsV1SystemsComplianceRequest{
+			Mode: styraclient.String(validationModeAll),
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errValidateCompliance)
+	}
+
+	obs := generateTestsObservation(tests.Payload)
+	switch {
+	case compliance != nil:
+		obs.Violations = countViolations(compliance.Payload)
+	case last != nil:
+		// Styra runs the compliance checks asynchronously if they take
+		// too long.
+		obs.Violations = last.Violations
+	}
+	return obs, nil
+}
+
+// isValidationDue returns whether the policies need to be validated at time
+// now given the last validation and the ID of the latest bundle.
+func isValidationDue(spec *v1alpha1.SystemValidation, last *v1alpha1.SystemValidationObservation, bundleID string, now time.Time) bool {
+	if last == nil || last.LastValidatedAt == nil || last.BundleID != bundleID {
+		return true
+	}
+	interval := defaultValidationInterval
+	if spec.Interval != nil {
+		interval = spec.Interval.Duration
+	}
+	return !now.Before(last.LastValidatedAt.Add(interval))
+}
+
+// generateTestsObservation generates the observation of the given policy
+// test results.
+func generateTestsObservation(resp *models.SystemsV1SystemsTestsResponse) *v1alpha1.SystemValidationObservation {
+	obs := &v1alpha1.SystemValidationObservation{}
+	if resp == nil {
+		return obs
+	}
+	for _, v := range resp.Result {
+		failed := v.AllFailedCount + v.AllErrorsCount
+		obs.Passed += v.AllCount - failed
+		obs.Failed += failed
+		for _, r := range v.All {
+			if r == nil || (!styraclient.BoolValue(r.Fail) && r.Error == "") {
+				continue
+			}
+			obs.FailedTests = append(obs.FailedTests, fmt.Sprintf("%s.%s", styraclient.StringValue(r.Package), styraclient.StringValue(r.Name)))
+		}
+	}
+	sort.Strings(obs.FailedTests)
+	return obs
+}
+
+// countViolations returns the number of violations reported by the given
+// compliance checks.
+func countViolations(resp *models.SystemsV1SystemsComplianceResponse) *int32 {
+	var violations int32
+	if resp != nil {
+		for _, v := range resp.Result {
+			violations += v.AllCount
+		}
+	}
+	return &violations
+}
+
+// validationCondition returns the PoliciesValid condition for the given
+// validation.
+func validationCondition(obs *v1alpha1.SystemValidationObservation) xpv1.Condition {
+	switch {
+	case obs.Failed > 0:
+		return v1alpha1.PolicyTestsFailed(obs.Failed, obs.FailedTests)
+	case obs.Violations != nil && *obs.Violations > 0:
+		return v1alpha1.ComplianceViolations(*obs.Violations)
+	default:
+		return v1alpha1.PoliciesValid(obs.Passed)
+	}
+}
+
+// removeCondition removes the condition of the given type from cr.
+func removeCondition(cr *v1alpha1.System, ct xpv1.ConditionType) {
+	for i, c := range cr.Status.Conditions {
+		if c.Type == ct {
+			cr.Status.Conditions = append(cr.Status.Conditions[:i:i], cr.Status.Conditions[i+1:]...)
+			return
+		}
+	}
+}
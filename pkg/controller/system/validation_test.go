@@ -0,0 +1,284 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package system
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	styra "github.com/mistermx/styra-go-client/pkg/client"
+	"github.com/mistermx/styra-go-client/pkg/client/systems"
+	"github.com/mistermx/styra-go-client/pkg/models"
+
+	"github.com/crossplane-contrib/provider-styra/apis/system/v1alpha1"
+	styraclient "github.com/crossplane-contrib/provider-styra/pkg/client"
+	mocksystem "github.com/crossplane-contrib/provider-styra/pkg/client/mock/systems"
+)
+
+func TestIsValidationDue(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	validatedAt := func(d time.Duration) *v1alpha1.SystemValidationObservation {
+		return &v1alpha1.SystemValidationObservation{
+			BundleID:        "bundle-1",
+			LastValidatedAt: &metav1.Time{Time: now.Add(-d)},
+		}
+	}
+
+	cases := map[string]struct {
+		spec     *v1alpha1.SystemValidation
+		last     *v1alpha1.SystemValidationObservation
+		bundleID string
+		want     bool
+	}{
+		"NeverValidated": {
+			spec:     &v1alpha1.SystemValidation{Enabled: true},
+			bundleID: "bundle-1",
+			want:     true,
+		},
+		"BundleChanged": {
+			spec:     &v1alpha1.SystemValidation{Enabled: true},
+			last:     validatedAt(time.Minute),
+			bundleID: "bundle-2",
+			want:     true,
+		},
+		"WithinDefaultInterval": {
+			spec:     &v1alpha1.SystemValidation{Enabled: true},
+			last:     validatedAt(time.Minute),
+			bundleID: "bundle-1",
+			want:     false,
+		},
+		"DefaultIntervalElapsed": {
+			spec:     &v1alpha1.SystemValidation{Enabled: true},
+			last:     validatedAt(defaultValidationInterval),
+			bundleID: "bundle-1",
+			want:     true,
+		},
+		"CustomIntervalElapsed": {
+			spec:     &v1alpha1.SystemValidation{Enabled: true, Interval: &metav1.Duration{Duration: time.Minute}},
+			last:     validatedAt(2 * time.Minute),
+			bundleID: "bundle-1",
+			want:     true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := isValidationDue(tc.spec, tc.last, tc.bundleID, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObserveValidation(t *testing.T) {
+	enabled := &v1alpha1.SystemValidation{Enabled: true}
+
+	expectTests := func(mcs *mocksystem.MockClientService) {
+		mcs.EXPECT().
+			ValidateSystemTests(&systems.ValidateSystemTestsParams{
+				Context: context.Background(),
+				System:  testSystemID,
+				Body: &models.SystemsV1SystemsTestsRequest{
+					Mode: styraclient.String(validationModeAll),
+				},
+			}).
+			Return(&systems.ValidateSystemTestsOK{
+				Payload: &models.SystemsV1SystemsTestsResponse{
+					Result: map[string]models.SystemsV1UnitTestsValidation{
+						"policy/test.rego": {
+							All: []*models.SystemsV1TesterResult{
+								{Package: styraclient.String("data.policy"), Name: styraclient.String("test_allow"), Fail: styraclient.Bool(false)},
+								{Package: styraclient.String("data.policy"), Name: styraclient.String("test_deny"), Fail: styraclient.Bool(true)},
+								{Package: styraclient.String("data.policy"), Name: styraclient.String("test_error"), Fail: styraclient.Bool(false), Error: "eval_conflict_error"},
+							},
+							AllCount:       3,
+							AllFailedCount: 1,
+							AllErrorsCount: 1,
+						},
+					},
+				},
+			}, nil)
+	}
+
+	type want struct {
+		obs        *v1alpha1.SystemValidationObservation
+		conditions []xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		spec       *v1alpha1.SystemValidation
+		last       *v1alpha1.SystemValidationObservation
+		conditions []xpv1.Condition
+		systems    *mocksystem.MockClientService
+		want       want
+	}{
+		"Disabled": {
+			spec:    &v1alpha1.SystemValidation{},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+		},
+		"DisabledAfterValidation": {
+			last: &v1alpha1.SystemValidationObservation{
+				LastValidatedAt: &metav1.Time{Time: time.Now()},
+				Passed:          1,
+			},
+			conditions: []xpv1.Condition{v1alpha1.PoliciesValid(1), v1alpha1.NoAgents()},
+			systems:    withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want: want{
+				conditions: []xpv1.Condition{v1alpha1.NoAgents()},
+			},
+		},
+		"NotDue": {
+			spec: enabled,
+			last: &v1alpha1.SystemValidationObservation{
+				LastValidatedAt: &metav1.Time{Time: time.Now()},
+				Passed:          1,
+			},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {}),
+			want: want{
+				obs: &v1alpha1.SystemValidationObservation{Passed: 1},
+			},
+		},
+		"TestsFailed": {
+			spec: enabled,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				expectTests(mcs)
+				mcs.EXPECT().
+					ValidateSystemCompliance(gomock.Any()).
+					Return(&systems.ValidateSystemComplianceOK{
+						Payload: &models.SystemsV1SystemsComplianceResponse{
+							Result: map[string]models.SystemsV1ComplianceValidation{
+								"validating": {AllCount: 2},
+							},
+						},
+					}, nil, nil)
+			}),
+			want: want{
+				obs: &v1alpha1.SystemValidationObservation{
+					Passed:      1,
+					Failed:      2,
+					FailedTests: []string{"data.policy.test_deny", "data.policy.test_error"},
+					Violations:  styraclient.Int32(2),
+				},
+				conditions: []xpv1.Condition{v1alpha1.PolicyTestsFailed(2, []string{"data.policy.test_deny", "data.policy.test_error"})},
+			},
+		},
+		"ComplianceAccepted": {
+			spec: enabled,
+			last: &v1alpha1.SystemValidationObservation{
+				BundleID:   "outdated",
+				Violations: styraclient.Int32(0),
+			},
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				mcs.EXPECT().
+					ValidateSystemTests(gomock.Any()).
+					Return(&systems.ValidateSystemTestsOK{
+						Payload: &models.SystemsV1SystemsTestsResponse{
+							Result: map[string]models.SystemsV1UnitTestsValidation{
+								"policy/test.rego": {AllCount: 4},
+							},
+						},
+					}, nil)
+				mcs.EXPECT().
+					ValidateSystemCompliance(gomock.Any()).
+					Return(nil, &systems.ValidateSystemComplianceAccepted{}, nil)
+			}),
+			want: want{
+				obs: &v1alpha1.SystemValidationObservation{
+					Passed:     4,
+					Violations: styraclient.Int32(0),
+				},
+				conditions: []xpv1.Condition{v1alpha1.PoliciesValid(4)},
+			},
+		},
+		"ComplianceFailed": {
+			spec: enabled,
+			systems: withMockSystem(t, func(mcs *mocksystem.MockClientService) {
+				expectTests(mcs)
+				mcs.EXPECT().
+					ValidateSystemCompliance(gomock.Any()).
+					Return(nil, nil, errBoom)
+			}),
+			want: want{
+				conditions: []xpv1.Condition{v1alpha1.ValidationUnknown(errors.Wrap(errBoom, errValidateCompliance))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := System(
+				withExternalName(testSystemID),
+				withSpec(v1alpha1.SystemParameters{
+					CustomSystemParameters: v1alpha1.CustomSystemParameters{
+						Validation: tc.spec,
+					},
+				}),
+				withConditions(tc.conditions...),
+			)
+			cr.Status.AtProvider.Validation = tc.last
+			e := &external{client: &styra.StyraAPI{Systems: tc.systems}, recorder: event.NewNopRecorder()}
+			e.observeValidation(context.Background(), cr, tc.last)
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider.Validation, cmpopts.IgnoreFields(v1alpha1.SystemValidationObservation{}, "LastValidatedAt")); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, cr.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidationCondition(t *testing.T) {
+	cases := map[string]struct {
+		obs  *v1alpha1.SystemValidationObservation
+		want xpv1.Condition
+	}{
+		"Valid": {
+			obs:  &v1alpha1.SystemValidationObservation{Passed: 3, Violations: styraclient.Int32(0)},
+			want: v1alpha1.PoliciesValid(3),
+		},
+		"ComplianceViolations": {
+			obs:  &v1alpha1.SystemValidationObservation{Passed: 3, Violations: styraclient.Int32(5)},
+			want: v1alpha1.ComplianceViolations(5),
+		},
+		"TestsFailed": {
+			obs:  &v1alpha1.SystemValidationObservation{Failed: 1, FailedTests: []string{"data.policy.test_deny"}, Violations: styraclient.Int32(5)},
+			want: v1alpha1.PolicyTestsFailed(1, []string{"data.policy.test_deny"}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validationCondition(tc.obs)
+			if diff := cmp.Diff(tc.want, got, test.EquateConditions()); diff != "" {
+				t.Errorf("r: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}